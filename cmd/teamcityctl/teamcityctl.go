@@ -59,18 +59,35 @@ func startBuild(c *cli.Context) error {
 		artfDependencyMap[dependency[0]], _ = strconv.Atoi(dependency[1])
 	}
 
-	id, err := client.StartBuild(
-		c.String("pipeline"),
-		c.String("branch"),
-		c.String("comment"),
-		paramsMap,
-		snapDependencyMap,
-		artfDependencyMap,
-	)
+	revisionMap := map[string]string{}
+	for _, v := range c.StringSlice("revision") {
+		revision := strings.Split(v, "=")
+		if len(revision) != 2 {
+			err := errors.New("Revision not provided in the form of vcsRootID=revision")
+			log.Println(err.Error())
+			return err
+		}
+		revisionMap[revision[0]] = revision[1]
+	}
+
+	details, err := client.StartBuildWithParams(teamcity.TCStartBuildParams{
+		BuildTypeID:            c.String("pipeline"),
+		Branch:                 c.String("branch"),
+		Comment:                c.String("comment"),
+		Params:                 paramsMap,
+		SnapshotDependencies:   snapDependencyMap,
+		ArtifactDependencies:   artfDependencyMap,
+		RebuildAllDependencies: c.Bool("rebuild-all-dependencies"),
+		RebuildDependencies:    c.StringSlice("rebuild-dependency"),
+		Revisions:              revisionMap,
+		Personal:               c.Bool("personal"),
+		AgentID:                c.Int("agent-id"),
+	})
 	if err != nil {
 		log.Println(err.Error())
 		return err
 	}
+	id := details.ID
 	log.Printf("Started build with ID: %d\n", id)
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{"#", "Build ID"})
@@ -199,6 +216,7 @@ func getBuilds(c *cli.Context) error {
 		User:        user,
 		Running:     running,
 		Cancelled:   cancelled,
+		AllBranches: c.Bool("all-branches"),
 		Start:       page,
 		Count:       count,
 		LookupLimit: 0,
@@ -213,7 +231,7 @@ func getBuilds(c *cli.Context) error {
 	switch c.String("format") {
 	case "table":
 		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"Id", "Pipeline", "Branch", "State", "Status"})
+		t.AppendHeader(table.Row{"Id", "Pipeline", "Branch", "State", "Status", "WebURL"})
 		for _, detail := range details.Builds {
 			t.AppendRow([]interface{}{
 				detail.ID,
@@ -221,6 +239,7 @@ func getBuilds(c *cli.Context) error {
 				detail.BranchName,
 				detail.State,
 				detail.Status,
+				detail.WebURL,
 			})
 		}
 		t.Render()
@@ -304,6 +323,27 @@ func main() {
 						Usage: "Provide multiple build artifact dependencies as buildPipelineID:buildID," +
 							" e.g --artifact-dependency myBuildConfigID1:uniqeBuildID1 --artifact-dependency  myBuildConfigID2:uniqeBuildID2",
 					},
+					&cli.StringSliceFlag{
+						Name: "revision",
+						Usage: "Provide multiple VCS root revisions to pin the build to as vcsRootID=revision," +
+							" e.g --revision myVcsRootID1=abc123 --revision myVcsRootID2=def456",
+					},
+					&cli.BoolFlag{
+						Name:  "personal",
+						Usage: "Trigger a personal build for the user the token belongs to",
+					},
+					&cli.IntFlag{
+						Name:  "agent-id",
+						Usage: "Provide the ID of the agent to run the build on",
+					},
+					&cli.BoolFlag{
+						Name:  "rebuild-all-dependencies",
+						Usage: "Rebuild all snapshot dependencies instead of reusing existing builds",
+					},
+					&cli.StringSliceFlag{
+						Name:  "rebuild-dependency",
+						Usage: "Provide multiple snapshot dependency pipeline IDs to rebuild, e.g --rebuild-dependency myBuildConfigID1",
+					},
 					&cli.StringFlag{
 						Name:  "comment",
 						Usage: "Provide text comment",
@@ -348,6 +388,10 @@ func main() {
 						Usage:       "Provide branch name for listing builds triggered on this branch",
 						DefaultText: "",
 					},
+					&cli.BoolFlag{
+						Name:  "all-branches",
+						Usage: "Show builds of all branches instead of the default branch only, ignored when branch is provided",
+					},
 					&cli.StringFlag{
 						Name:        "status",
 						Usage:       "Show builds with this status. Accepted values [SUCCESS FAILURE UNKNOWN]",
@@ -1,6 +1,6 @@
 module github.com/raghuP9/buildserver-client
 
-go 1.13
+go 1.16
 
 require (
 	github.com/go-openapi/strfmt v0.19.5 // indirect
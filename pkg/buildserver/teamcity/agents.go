@@ -0,0 +1,55 @@
+package teamcity
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// SetAgentAuthorized authorizes or unauthorizes an agent,
+// the comment is recorded by teamcity along with the change
+func (t *TCClient) SetAgentAuthorized(agentID int, authorized bool, comment string) error {
+	return t.setAgentStatus(agentID, "authorizedInfo", authorized, comment)
+}
+
+// SetAgentEnabled enables or disables an agent,
+// the comment is recorded by teamcity along with the change
+func (t *TCClient) SetAgentEnabled(agentID int, enabled bool, comment string) error {
+	return t.setAgentStatus(agentID, "enabledInfo", enabled, comment)
+}
+
+func (t *TCClient) setAgentStatus(agentID int, info string, status bool, comment string) error {
+	payload := TCAgentStatusInfo{
+		Status: status,
+		Comment: TCBuildComment{
+			Text: comment,
+		},
+	}
+	return t.doJSON(context.Background(), "PUT", t.restURL("/agents/id:%d/%s", agentID, info), payload, nil)
+}
+
+// GetAgentBuildCount returns the number of builds the agent has run
+// since the provided time, on any branch, personal and cancelled
+// builds included
+func (t *TCClient) GetAgentBuildCount(agentName string, since time.Time) (int, error) {
+	return t.countAllBuilds(NewLocator().
+		Agent(agentName).
+		SinceDate(since).
+		AnyBranch().
+		Dimension("personal", "any").
+		Dimension("canceled", "any"))
+}
+
+// GetCompatibleAgents returns the agents able to run builds of the
+// build pipeline. No compatible agents means queued builds of the
+// pipeline wait in the queue until a compatible agent shows up.
+func (t *TCClient) GetCompatibleAgents(buildTypeID string) ([]TCAgent, error) {
+	locator := NewLocator().Nested("compatible", NewLocator().BuildType(buildTypeID))
+	var agents TCAgents
+	err := t.getJSON(t.restURL("/agents?locator=%s&fields=agent(id,name,typeId,connected,enabled,authorized,href,webUrl,pool(id,name))",
+		url.QueryEscape(locator.String())), &agents)
+	if err != nil {
+		return nil, err
+	}
+	return agents.Agent, nil
+}
@@ -0,0 +1,206 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// progressWriter counts the bytes written through it and
+// reports them to the progress callback after every write
+type progressWriter struct {
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.progress != nil {
+		p.progress(p.written, p.total)
+	}
+	return len(b), nil
+}
+
+/*
+DownloadArtifactToFile streams an artifact file of a build to the local disk
+
+id is the build id from which the artifact will be fetched
+
+artifactPath is the relative path of the file in teamcity artifacts
+
+localPath is the path of the file the artifact will be written to,
+the file is created or truncated if it already exists
+
+progress, if not nil, is called as the download advances with the
+number of bytes written so far and the total size of the artifact.
+total is -1 when the server does not send the Content-Length header.
+*/
+func (t *TCClient) DownloadArtifactToFile(
+	ctx context.Context,
+	id int,
+	artifactPath, localPath string,
+	progress func(written, total int64),
+) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.restURL("/builds/id:%d/artifacts/content/%s", id, artifactPath), nil)
+	if err != nil {
+		return err
+	}
+	t.setAuthorizationHeader(req.Header)
+	req.Header.Add("Accept", "*/*")
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err = checkResponse(resp); err != nil {
+		return buildNotFound(err)
+	}
+
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+
+	// ContentLength is -1 when the Content-Length header is not set
+	counter := &progressWriter{total: resp.ContentLength, progress: progress}
+	if _, err = io.Copy(io.MultiWriter(f, counter), resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+/*
+UploadArtifact uploads content read from r as an artifact file of a build
+
+id is the build id the artifact will be attached to
+
+artifactPath is the relative path of the file in teamcity artifacts,
+its extension decides the Content-Type of the upload
+*/
+func (t *TCClient) UploadArtifact(ctx context.Context, id int, artifactPath string, r io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, "PUT", t.restURL("/builds/id:%d/artifacts/files/%s", id, artifactPath), r)
+	if err != nil {
+		return err
+	}
+	t.setAuthorizationHeader(req.Header)
+
+	contentType := mime.TypeByExtension(path.Ext(artifactPath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Add("Content-Type", contentType)
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp)
+}
+
+// GetArtifactMetadata returns the name, size and modification time
+// of an artifact file without downloading its content
+func (t *TCClient) GetArtifactMetadata(id int, path string) (TCArtifactMeta, error) {
+	var meta TCArtifactMeta
+	err := t.getJSON(t.restURL("/builds/id:%d/artifacts/metadata/%s", id, path), &meta)
+	return meta, err
+}
+
+/*
+DownloadArtifactsMatching downloads the artifact files of a build that
+match the glob pattern, as per path.Match, into destDir and returns the
+paths of the written files
+
+The artifacts are listed recursively. A pattern without a slash, e.g.
+"*.xml", is matched against the file names in any directory, otherwise
+it is matched against the path of the files relative to the artifacts
+root. The files keep their relative path under destDir.
+*/
+func (t *TCClient) DownloadArtifactsMatching(ctx context.Context, id int, pattern, destDir string) ([]string, error) {
+	// Validate the pattern once instead of on every file
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	files, err := t.listArtifactFiles(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	written := []string{}
+	for _, file := range files {
+		name := file
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(file)
+		}
+		if matched, _ := path.Match(pattern, name); !matched {
+			continue
+		}
+
+		localPath, err := localArtifactPath(destDir, file)
+		if err != nil {
+			return written, err
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return written, err
+		}
+		if err := t.DownloadArtifactToFile(ctx, id, file, localPath, nil); err != nil {
+			return written, err
+		}
+		written = append(written, localPath)
+	}
+	return written, nil
+}
+
+// localArtifactPath returns the path under destDir the artifact file is
+// written to. It fails for paths escaping destDir, e.g. with "../", as
+// the artifact paths come from the server.
+func localArtifactPath(destDir, file string) (string, error) {
+	localPath := filepath.Join(destDir, filepath.FromSlash(file))
+	rel, err := filepath.Rel(filepath.Clean(destDir), localPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Artifact %s would be written outside of %s", file, destDir)
+	}
+	return localPath, nil
+}
+
+// listArtifactFiles returns the paths of the artifact files of a build
+// under the directory dir, relative to the artifacts root, recursively
+func (t *TCClient) listArtifactFiles(ctx context.Context, id int, dir string) ([]string, error) {
+	var children TCArtifactFiles
+	err := t.doJSON(ctx, "GET", t.restURL("/builds/id:%d/artifacts/children/%s", id, dir), nil, &children)
+	if err != nil {
+		return nil, buildNotFound(err)
+	}
+
+	files := []string{}
+	for _, child := range children.File {
+		childPath := path.Join(dir, child.Name)
+		// Archives are downloaded as a whole rather than browsed into
+		if child.Content != nil {
+			files = append(files, childPath)
+			continue
+		}
+
+		nested, err := t.listArtifactFiles(ctx, id, childPath)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, nested...)
+	}
+	return files, nil
+}
@@ -0,0 +1,35 @@
+package teamcity
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalArtifactPath(t *testing.T) {
+	destDir := filepath.Join("tmp", "artifacts")
+	tests := []struct {
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{file: "report.xml", want: filepath.Join(destDir, "report.xml")},
+		{file: "reports/unit/report.xml", want: filepath.Join(destDir, "reports", "unit", "report.xml")},
+		{file: "reports/../report.xml", want: filepath.Join(destDir, "report.xml")},
+		{file: "/report.xml", want: filepath.Join(destDir, "report.xml")},
+		{file: "..", wantErr: true},
+		{file: "../report.xml", wantErr: true},
+		{file: "reports/../../../etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := localArtifactPath(destDir, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("localArtifactPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("localArtifactPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
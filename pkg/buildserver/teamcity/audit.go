@@ -0,0 +1,42 @@
+package teamcity
+
+import (
+	"sort"
+)
+
+// GetBuildAudit returns the timeline of the actions recorded on a build,
+// sorted by time. Teamcity keeps only the latest comment and pin state
+// of a build, so there is at most one entry of each action.
+func (t *TCClient) GetBuildAudit(id int) ([]TCAuditEntry, error) {
+	var details TCBuildDetails
+	err := t.GetBuildFields(id, "id,comment(text,timestamp,user),pinInfo(status,text,timestamp,user)", &details)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []TCAuditEntry{}
+	if details.Comment.Text != "" || details.Comment.Timestamp != "" {
+		entries = append(entries, TCAuditEntry{
+			Action:    "comment",
+			Text:      details.Comment.Text,
+			Timestamp: details.Comment.Timestamp,
+			User:      details.Comment.User,
+		})
+	}
+
+	if details.PinInfo != nil && details.PinInfo.Status {
+		entries = append(entries, TCAuditEntry{
+			Action:    "pin",
+			Text:      details.PinInfo.Text,
+			Timestamp: details.PinInfo.Timestamp,
+			User:      details.PinInfo.User,
+		})
+	}
+
+	// Teamcity timestamps sort chronologically as strings
+	// as long as they are in the same timezone
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+	return entries, nil
+}
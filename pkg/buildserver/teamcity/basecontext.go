@@ -0,0 +1,42 @@
+package teamcity
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// WithBaseContext ties all the calls of the client to ctx, in addition
+// to their own context. Once ctx is cancelled, the calls in flight are
+// aborted and the subsequent calls fail with the context error.
+func WithBaseContext(ctx context.Context) TCClientOption {
+	return func(t *TCClient) {
+		t.baseCtx = ctx
+	}
+}
+
+// withBaseContext returns the request with a context cancelled when
+// either the request context or the base context is done. cancel must
+// be called to release the context once the request is over.
+func (t *TCClient) withBaseContext(req *http.Request) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(req.Context())
+	go func() {
+		select {
+		case <-t.baseCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return req.WithContext(ctx), cancel
+}
+
+// cancelOnClose releases the request context once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
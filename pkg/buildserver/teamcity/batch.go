@@ -0,0 +1,202 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BatchError collects the errors of a batch operation, keyed by
+// the item that failed, e.g. the build id
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for k := range e.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, k := range keys {
+		messages = append(messages, fmt.Sprintf("%s: %s", k, e.Errors[k].Error()))
+	}
+	return fmt.Sprintf("%d operations failed: %s", len(keys), strings.Join(messages, "; "))
+}
+
+// forEachID calls fn for every id using at most concurrency goroutines
+// and keeps going past failures. Ids not processed because ctx is done
+// fail with the context error. It returns a *BatchError if any call failed.
+func forEachID(ctx context.Context, ids []int, concurrency int, fn func(id int) error) error {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, strconv.Itoa(id))
+	}
+	return forEachKey(ctx, keys, concurrency, func(key string) error {
+		id, _ := strconv.Atoi(key)
+		return fn(id)
+	})
+}
+
+// forEachKey is forEachID for items identified by a string
+func forEachKey(ctx context.Context, keys []string, concurrency int, fn func(key string) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = map[string]error{}
+	)
+	jobs := make(chan string)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				err := ctx.Err()
+				if err == nil {
+					err = fn(key)
+				}
+				if err != nil {
+					mu.Lock()
+					errs[key] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &BatchError{Errors: errs}
+	}
+	return nil
+}
+
+// StopBuilds stops the running builds with the provided ids using
+// at most concurrency parallel requests. It keeps going when stopping
+// a build fails and returns a *BatchError with the error of every id
+// that could not be stopped.
+func (t *TCClient) StopBuilds(ctx context.Context, ids []int, comment string, concurrency int) error {
+	return forEachID(ctx, ids, concurrency, func(id int) error {
+		return t.StopBuildContext(ctx, id, comment)
+	})
+}
+
+/*
+StartBuildsForBranches queues a build of the build pipeline for every
+branch using at most concurrency parallel requests, and returns the id
+of the queued build of every branch
+
+It keeps going when queueing a build fails, the returned map then only
+holds the branches whose build was queued and the error is a *BatchError
+keyed by the branches that failed.
+*/
+func (t *TCClient) StartBuildsForBranches(
+	ctx context.Context,
+	buildTypeID string,
+	branches []string,
+	comment string,
+	params map[string]string,
+	concurrency int,
+) (map[string]int, error) {
+	var mu sync.Mutex
+	ids := map[string]int{}
+
+	err := forEachKey(ctx, branches, concurrency, func(branch string) error {
+		details, err := t.StartBuildWithParamsContext(ctx, TCStartBuildParams{
+			BuildTypeID: buildTypeID,
+			Branch:      branch,
+			Comment:     comment,
+			Params:      params,
+		})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		ids[branch] = details.ID
+		mu.Unlock()
+		return nil
+	})
+	return ids, err
+}
+
+// ErrUnrestrictedDelete is returned by DeleteBuildsByLocator when the
+// query params could match every build of the server
+var ErrUnrestrictedDelete = errors.New("refusing to delete builds without a restrictive filter")
+
+/*
+DeleteBuildsByLocator deletes the builds matching the query params one
+after the other and returns the number of builds deleted
+
+All the matching builds are listed page by page before any is deleted,
+unless Count or Start are set, in which case only the builds of that
+page are deleted. It keeps going when deleting a build fails and returns
+a *BatchError with the error of every id that could not be deleted.
+
+To prevent mass deletion by mistake, at least one of BuildTypeID, Branch,
+Status, User, Agent or Tags must be set, or Pinned or Personal must be
+true. Count alone does not count as a filter, and neither does Pinned
+or Personal set to false as most builds match it. Otherwise
+ErrUnrestrictedDelete is returned.
+*/
+func (t *TCClient) DeleteBuildsByLocator(ctx context.Context, params TCQueryParams) (int, error) {
+	if !params.restrictive() {
+		return 0, ErrUnrestrictedDelete
+	}
+
+	// Listing every build before deleting any keeps the
+	// deletions from shifting the builds between pages
+	var builds []TCBuildDetails
+	if params.Count > 0 || params.Start > 0 {
+		page, err := t.GetAllBuilds(params)
+		if err != nil {
+			return 0, err
+		}
+		builds = page.Builds
+	} else {
+		all, err := t.listAllBuilds(buildsLocator(params))
+		if err != nil {
+			return 0, err
+		}
+		builds = all
+	}
+
+	ids := make([]int, 0, len(builds))
+	for _, build := range builds {
+		ids = append(ids, build.ID)
+	}
+
+	err := forEachID(ctx, ids, 1, func(id int) error {
+		return t.DeleteBuild(ctx, id)
+	})
+
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		return len(ids) - len(batchErr.Errors), err
+	}
+	return len(ids), err
+}
+
+// restrictive reports whether the query params filter builds on
+// more than their position, so that they cannot match every build.
+// Pinned and Personal only narrow the builds down when true.
+func (p TCQueryParams) restrictive() bool {
+	return p.BuildTypeID != "" || p.Branch != "" || p.Status != "" || p.User != "" || p.Agent != "" ||
+		len(p.Tags) > 0 || (p.Pinned != nil && *p.Pinned) || (p.Personal != nil && *p.Personal)
+}
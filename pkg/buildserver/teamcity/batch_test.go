@@ -0,0 +1,127 @@
+package teamcity_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity/teamcitytest"
+)
+
+func TestDeleteBuildsByLocator(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for id := 1; id <= 1250; id++ {
+		server.AddBuild(teamcity.TCBuildDetails{ID: id, BuildTypeID: "Pipeline", State: teamcity.StateFinished})
+	}
+	server.AddBuild(teamcity.TCBuildDetails{ID: 2000, BuildTypeID: "Other", State: teamcity.StateFinished})
+	server.Handle(http.MethodDelete, "/builds/id:7", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Error: Build is in use", http.StatusConflict)
+	})
+
+	deleted, err := client.DeleteBuildsByLocator(context.Background(), teamcity.TCQueryParams{BuildTypeID: "Pipeline"})
+	var batchErr *teamcity.BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 || batchErr.Errors["7"] == nil {
+		t.Fatalf("DeleteBuildsByLocator() error = %v, want a batch error for build 7", err)
+	}
+	if deleted != 1249 {
+		t.Errorf("DeleteBuildsByLocator() = %d, want 1249", deleted)
+	}
+
+	for _, id := range []int{1, 100, 101, 1250} {
+		if _, ok := server.Build(id); ok {
+			t.Errorf("build %d was not deleted", id)
+		}
+	}
+	for _, id := range []int{7, 2000} {
+		if _, ok := server.Build(id); !ok {
+			t.Errorf("build %d was deleted", id)
+		}
+	}
+}
+
+func TestDeleteBuildsByLocatorPage(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for id := 1; id <= 20; id++ {
+		server.AddBuild(teamcity.TCBuildDetails{ID: id, BuildTypeID: "Pipeline", State: teamcity.StateFinished})
+	}
+
+	deleted, err := client.DeleteBuildsByLocator(context.Background(), teamcity.TCQueryParams{BuildTypeID: "Pipeline", Count: 5})
+	if err != nil {
+		t.Fatalf("DeleteBuildsByLocator() error = %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("DeleteBuildsByLocator() = %d, want 5", deleted)
+	}
+	if _, ok := server.Build(15); !ok {
+		t.Errorf("build 15 out of the page was deleted")
+	}
+}
+
+func TestDeleteBuildsByLocatorUnrestricted(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	server.AddBuild(teamcity.TCBuildDetails{ID: 1, BuildTypeID: "Pipeline", State: teamcity.StateFinished, PinInfo: &teamcity.TCPinInfo{Status: true}})
+
+	yes, no := true, false
+	tests := []struct {
+		name    string
+		params  teamcity.TCQueryParams
+		wantErr error
+	}{
+		{"no filter", teamcity.TCQueryParams{}, teamcity.ErrUnrestrictedDelete},
+		{"count only", teamcity.TCQueryParams{Count: 10}, teamcity.ErrUnrestrictedDelete},
+		{"not pinned", teamcity.TCQueryParams{Pinned: &no}, teamcity.ErrUnrestrictedDelete},
+		{"not personal", teamcity.TCQueryParams{Personal: &no}, teamcity.ErrUnrestrictedDelete},
+		{"not pinned nor personal", teamcity.TCQueryParams{Pinned: &no, Personal: &no}, teamcity.ErrUnrestrictedDelete},
+		{"pinned", teamcity.TCQueryParams{Pinned: &yes}, nil},
+		{"personal", teamcity.TCQueryParams{Personal: &yes}, nil},
+		{"build type", teamcity.TCQueryParams{BuildTypeID: "Other"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.DeleteBuildsByLocator(context.Background(), tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteBuildsByLocator() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStartBuildsForBranchesContext(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	server.Handle(http.MethodPost, "/buildQueue", func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	ids, err := client.StartBuildsForBranches(ctx, "Pipeline", []string{"main"}, "", nil, 1)
+	if err == nil || len(ids) != 0 {
+		t.Fatalf("StartBuildsForBranches() = %v, %v, want an error", ids, err)
+	}
+	if elapsed := time.Since(begin); elapsed > 2*time.Second {
+		t.Errorf("StartBuildsForBranches() returned after %s, want the request bound to ctx", elapsed)
+	}
+}
@@ -0,0 +1,73 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without making a request while the
+// circuit breaker is open after consecutive failures
+var ErrCircuitOpen = errors.New("circuit breaker open, teamcity is failing")
+
+// circuitBreaker fails calls fast after threshold consecutive failures,
+// until cooldown has passed and a probe request succeeds
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// WithCircuitBreaker makes the client fail fast with ErrCircuitOpen
+// after threshold consecutive calls failed with a network error or
+// a 5xx status code. Once cooldown has passed a single probe request
+// is let through, the circuit closes again if it succeeds.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) TCClientOption {
+	return func(t *TCClient) {
+		if threshold > 0 {
+			t.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+		}
+	}
+}
+
+// allow returns ErrCircuitOpen if the call must not be made
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *circuitBreaker) record(resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if errors.Is(err, context.Canceled) {
+		// Cancelled by the caller, says nothing about teamcity
+		return
+	}
+	if err == nil && resp.StatusCode < 500 {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,240 @@
+package teamcity
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// buildLogURL returns the URL of the downloadable build log,
+// which is served outside of the REST API
+func (t *TCClient) buildLogURL(id int) string {
+	root := ""
+	if t.guest {
+		root = "/guestAuth"
+	}
+	return fmt.Sprintf("%s%s/downloadBuildLog.html?buildId=%d", t.serverURL, root, id)
+}
+
+/*
+SaveBuildLog streams the build log of a build to <dir>/build-<id>.log
+and returns the path of the written file
+
+dir is created if it does not exist
+*/
+func (t *TCClient) SaveBuildLog(ctx context.Context, id int, dir string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.buildLogURL(id), nil)
+	if err != nil {
+		return "", err
+	}
+	t.setAuthorizationHeader(req.Header)
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err = checkResponse(resp); err != nil {
+		return "", buildNotFound(err)
+	}
+
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	logPath := filepath.Join(dir, fmt.Sprintf("build-%d.log", id))
+	f, err := os.Create(logPath)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(logPath)
+		return "", err
+	}
+
+	return logPath, f.Close()
+}
+
+/*
+TailBuildLog writes the build log of a build to out as it grows, like
+tail -f, until the build is finished or ctx is done
+
+The log is polled every poll interval of the client, only asking for
+the content past what was already written.
+*/
+func (t *TCClient) TailBuildLog(ctx context.Context, id int, out io.Writer) error {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	var offset int64
+	for {
+		// Read the state before the log so that the content
+		// written up to the end of the build is not missed
+		var details TCBuildDetails
+		if err := t.GetBuildFieldsContext(ctx, id, "state", &details); err != nil {
+			return err
+		}
+
+		written, err := t.copyBuildLogFrom(ctx, id, offset, out)
+		offset += written
+		if err != nil {
+			return err
+		}
+		if details.State == StateFinished {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// copyBuildLogFrom writes the build log past offset to out
+// and returns the number of bytes written
+func (t *TCClient) copyBuildLogFrom(ctx context.Context, id int, offset int64, out io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", t.buildLogURL(id), nil)
+	if err != nil {
+		return 0, err
+	}
+	t.setAuthorizationHeader(req.Header)
+	if offset > 0 {
+		// The range must apply to the log itself, not to its gzip encoding
+		req.Header.Set("Accept-Encoding", "identity")
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// Nothing new since the last read
+		return 0, nil
+	}
+	if err = checkResponse(resp); err != nil {
+		return 0, buildNotFound(err)
+	}
+
+	// The whole log is sent back when the range is not supported
+	if resp.StatusCode != http.StatusPartialContent && offset > 0 {
+		if _, err = io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			if err == io.EOF {
+				return 0, nil
+			}
+			return 0, err
+		}
+	}
+	return io.Copy(out, resp.Body)
+}
+
+// logLine matches a message line of the downloadable build log, e.g.
+// "[12:00:01]W:\t\t [Step 1/2] message", made of the timestamp, the
+// status and one more tab than the nesting level of the message
+var logLine = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2})\](.):(\t+) ?(.*)$`)
+
+/*
+GetBuildLogMessages returns count messages of the build log starting
+at the index start, with their timestamp and their nesting level in
+the log blocks
+
+The messages are parsed from the downloadable build log, which is read
+only up to the requested page. IDs are the position of the messages in
+the log starting at 1, lines that do not start a message are appended
+to the text of the message they continue.
+*/
+func (t *TCClient) GetBuildLogMessages(id int, start, count int) ([]TCLogMessage, error) {
+	req, err := http.NewRequestWithContext(context.Background(), "GET", t.buildLogURL(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	t.setAuthorizationHeader(req.Header)
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err = checkResponse(resp); err != nil {
+		return nil, buildNotFound(err)
+	}
+
+	messages := []TCLogMessage{}
+	var current *TCLogMessage
+	// ID of the innermost block open at every nesting level
+	blocks := []int{}
+	index := 0
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		match := logLine.FindStringSubmatch(line)
+		if match == nil && current != nil {
+			current.Text += "\n" + line
+			continue
+		}
+
+		// A new message starts, the page is full once it is past the page
+		if index >= start+count {
+			break
+		}
+		index++
+
+		message := TCLogMessage{ID: index, Text: line, Status: 1}
+		if match != nil {
+			message.Timestamp = match[1]
+			message.Status = logStatus(match[2])
+			message.Level = len(match[3]) - 1
+			message.Text = match[4]
+		}
+
+		if message.Level > len(blocks) {
+			message.Level = len(blocks)
+		}
+		blocks = append(blocks[:message.Level], message.ID)
+		if message.Level > 0 {
+			message.ParentID = blocks[message.Level-1]
+		}
+
+		current = nil
+		if index > start {
+			messages = append(messages, message)
+			current = &messages[len(messages)-1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// logStatus returns the status of a build log message from
+// the status character of its line
+func logStatus(status string) int {
+	switch status {
+	case "W":
+		return 2
+	case "E", "F":
+		return 4
+	default:
+		return 1
+	}
+}
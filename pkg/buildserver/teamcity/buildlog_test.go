@@ -0,0 +1,51 @@
+package teamcity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testBuildLog = "[12:00:00] :\t [Step 1/1] Step 1/1: Test\n" +
+	"[12:00:01] :\t\t [Step 1/1] Starting: make test\n" +
+	"[12:00:02]W:\t\t [Step 1/1] deprecated flag\n" +
+	"second line of the warning\n" +
+	"[12:00:03]E:\t\t\t [Step 1/1] test failed\n" +
+	"[12:00:04] :\t [Step 1/1] Process exited with code 1\n"
+
+func TestGetBuildLogMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/downloadBuildLog.html" || r.URL.Query().Get("buildId") != "42" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testBuildLog))
+	}))
+	defer server.Close()
+
+	client := NewTeamcityClient(time.Second, time.Second, time.Second, server.URL, "token", false)
+
+	messages, err := client.GetBuildLogMessages(42, 1, 3)
+	if err != nil {
+		t.Fatalf("GetBuildLogMessages() error = %v", err)
+	}
+
+	want := []TCLogMessage{
+		{ID: 2, ParentID: 1, Level: 1, Text: "[Step 1/1] Starting: make test", Timestamp: "12:00:01", Status: 1},
+		{ID: 3, ParentID: 1, Level: 1, Text: "[Step 1/1] deprecated flag\nsecond line of the warning", Timestamp: "12:00:02", Status: 2},
+		{ID: 4, ParentID: 3, Level: 2, Text: "[Step 1/1] test failed", Timestamp: "12:00:03", Status: 4},
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("GetBuildLogMessages() = %+v, want %+v", messages, want)
+	}
+
+	messages, err = client.GetBuildLogMessages(42, 10, 5)
+	if err != nil {
+		t.Fatalf("GetBuildLogMessages() error = %v", err)
+	}
+	if len(messages) != 0 {
+		t.Errorf("GetBuildLogMessages() past the end = %+v, want none", messages)
+	}
+}
@@ -0,0 +1,138 @@
+package teamcity_test
+
+import (
+	"testing"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity/teamcitytest"
+)
+
+func TestCountBuilds(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for id := 1; id <= 2345; id++ {
+		status := teamcity.StatusSuccess
+		if id%5 == 0 {
+			status = teamcity.StatusFailure
+		}
+		server.AddBuild(teamcity.TCBuildDetails{ID: id, BuildTypeID: "Pipeline", State: teamcity.StateFinished, Status: status})
+	}
+	server.AddBuild(teamcity.TCBuildDetails{ID: 3000, BuildTypeID: "Other", State: teamcity.StateFinished, Status: teamcity.StatusSuccess})
+
+	tests := []struct {
+		name   string
+		params teamcity.TCQueryParams
+		want   int
+	}{
+		{"all pages", teamcity.TCQueryParams{BuildTypeID: "Pipeline"}, 2345},
+		{"filtered", teamcity.TCQueryParams{BuildTypeID: "Pipeline", Status: "FAILURE"}, 469},
+		{"single page", teamcity.TCQueryParams{BuildTypeID: "Other"}, 1},
+		{"explicit count", teamcity.TCQueryParams{BuildTypeID: "Pipeline", Count: 150}, 150},
+		{"explicit start", teamcity.TCQueryParams{BuildTypeID: "Pipeline", Start: 2300}, 45},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.CountBuilds(tt.params)
+			if err != nil {
+				t.Fatalf("CountBuilds() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountBuilds() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPinnedBuilds(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	want := map[int]bool{}
+	for id := 1; id <= 1800; id++ {
+		build := teamcity.TCBuildDetails{ID: id, BuildTypeID: "Pipeline", State: teamcity.StateFinished, BranchName: "main", DefaultBranch: true}
+		if id%2 == 0 {
+			build.BranchName, build.DefaultBranch = "feature", false
+		}
+		build.Personal = id%7 == 0
+		if id%11 == 0 {
+			build.CanceledInfo = &teamcity.TCCanceledInfo{Text: "Stopped"}
+		}
+		if id%3 != 0 {
+			build.PinInfo = &teamcity.TCPinInfo{Status: true}
+			want[id] = true
+		}
+		server.AddBuild(build)
+	}
+	server.AddBuild(teamcity.TCBuildDetails{ID: 2000, BuildTypeID: "Other", State: teamcity.StateFinished, PinInfo: &teamcity.TCPinInfo{Status: true}})
+
+	builds, err := client.GetPinnedBuilds("Pipeline")
+	if err != nil {
+		t.Fatalf("GetPinnedBuilds() error = %v", err)
+	}
+	if builds.Count != len(want) || len(builds.Builds) != len(want) {
+		t.Fatalf("GetPinnedBuilds() = %d builds, count %d, want %d", len(builds.Builds), builds.Count, len(want))
+	}
+	for _, build := range builds.Builds {
+		if !want[build.ID] {
+			t.Errorf("GetPinnedBuilds() returned build %d which is not pinned", build.ID)
+		}
+		delete(want, build.ID)
+	}
+}
+
+func TestGetDependentBuilds(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	// The fake ignores the snapshotDependency dimension,
+	// so every build stands for a dependent build
+	builds := []teamcity.TCBuildDetails{
+		{ID: 1, BuildTypeID: "Deploy", State: teamcity.StateFinished, BranchName: "main", DefaultBranch: true},
+		{ID: 2, BuildTypeID: "Deploy", State: teamcity.StateFinished, BranchName: "feature"},
+		{ID: 3, BuildTypeID: "Deploy", State: teamcity.StateRunning, BranchName: "feature"},
+		{ID: 4, BuildTypeID: "Deploy", State: teamcity.StateQueued, BranchName: "main", DefaultBranch: true},
+		{ID: 5, BuildTypeID: "Deploy", State: teamcity.StateFinished, Personal: true},
+		{ID: 6, BuildTypeID: "Deploy", State: teamcity.StateFinished, CanceledInfo: &teamcity.TCCanceledInfo{Text: "Stopped"}},
+	}
+	for _, build := range builds {
+		server.AddBuild(build)
+	}
+
+	dependents, err := client.GetDependentBuilds(100)
+	if err != nil {
+		t.Fatalf("GetDependentBuilds() error = %v", err)
+	}
+	if len(dependents) != len(builds) {
+		t.Errorf("GetDependentBuilds() = %d builds, want %d", len(dependents), len(builds))
+	}
+}
+
+func TestGetBuildsByRevision(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	// The fake ignores the revision dimension,
+	// so every build stands for a build of the revision
+	states := []teamcity.BuildState{teamcity.StateFinished, teamcity.StateRunning, teamcity.StateQueued}
+	for id := 1; id <= 1500; id++ {
+		build := teamcity.TCBuildDetails{ID: id, BuildTypeID: "Pipeline", State: states[id%len(states)], BranchName: "main", DefaultBranch: true}
+		if id%2 == 0 {
+			build.BranchName, build.DefaultBranch = "feature", false
+		}
+		build.Personal = id%7 == 0
+		server.AddBuild(build)
+	}
+
+	builds, err := client.GetBuildsByRevision("Repo", "0a1b2c3d")
+	if err != nil {
+		t.Fatalf("GetBuildsByRevision() error = %v", err)
+	}
+	if builds.Count != 1500 || len(builds.Builds) != 1500 {
+		t.Errorf("GetBuildsByRevision() = %d builds, count %d, want 1500", len(builds.Builds), builds.Count)
+	}
+}
@@ -0,0 +1,139 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetBuildTypeSettings returns the settings of a build pipeline,
+// such as buildNumberCounter and checkoutMode, as a map of
+// setting name to value
+func (t *TCClient) GetBuildTypeSettings(buildTypeID string) (map[string]string, error) {
+	var settings TCBuildProperties
+	if err := t.getJSON(t.restURL("/buildTypes/id:%s/settings", buildTypeID), &settings); err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for _, setting := range settings.Property {
+		result[setting.Name] = setting.Value
+	}
+	return result, nil
+}
+
+// GetBuildNumberFormat returns the build number format of a build
+// pipeline, e.g. "1.0.%build.counter%". The resolved build number of
+// a build is in the Number field of TCBuildDetails.
+func (t *TCClient) GetBuildNumberFormat(buildTypeID string) (string, error) {
+	settings, err := t.GetBuildTypeSettings(buildTypeID)
+	if err != nil {
+		return "", err
+	}
+
+	format, ok := settings["buildNumberPattern"]
+	if !ok {
+		return "", fmt.Errorf("Build pipeline %s has no buildNumberPattern setting", buildTypeID)
+	}
+	return format, nil
+}
+
+// GetBuildType returns the build pipeline with its parameters
+func (t *TCClient) GetBuildType(buildTypeID string) (TCBuildType, error) {
+	var buildType TCBuildType
+	err := t.getJSON(t.restURL("/buildTypes/id:%s?fields=id,name,description,projectName,projectId,webUrl,"+
+		"parameters(property(name,value,type(rawValue)))", buildTypeID), &buildType)
+	return buildType, err
+}
+
+// GetBuildTypeTemplate returns the template the build pipeline is based on
+func (t *TCClient) GetBuildTypeTemplate(buildTypeID string) (TCBuildType, error) {
+	var template TCBuildType
+	err := t.getJSON(t.restURL("/buildTypes/id:%s/template?fields=id,name,description,projectName,projectId,webUrl",
+		buildTypeID), &template)
+	return template, err
+}
+
+// AttachTemplate bases the build pipeline on the template
+func (t *TCClient) AttachTemplate(buildTypeID, templateID string) error {
+	_, err := t.doText(context.Background(), "PUT", t.restURL("/buildTypes/id:%s/template", buildTypeID), "id:"+templateID)
+	return err
+}
+
+// GetBuildCounter returns the counter used for the number of
+// the next build of the build pipeline
+func (t *TCClient) GetBuildCounter(buildTypeID string) (int, error) {
+	counter, err := t.doText(context.Background(), "GET",
+		t.restURL("/buildTypes/id:%s/settings/buildNumberCounter", buildTypeID), "")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(counter))
+}
+
+// SetBuildCounter sets the counter used for the number of the next
+// build of the build pipeline, teamcity errors are returned as is
+func (t *TCClient) SetBuildCounter(buildTypeID string, value int) error {
+	_, err := t.doText(context.Background(), "PUT",
+		t.restURL("/buildTypes/id:%s/settings/buildNumberCounter", buildTypeID), strconv.Itoa(value))
+	return err
+}
+
+// GetBuildTypeTriggers returns the triggers of the build pipeline,
+// including the ones inherited from its template, with their settings
+func (t *TCClient) GetBuildTypeTriggers(buildTypeID string) ([]TCTrigger, error) {
+	var triggers TCTriggers
+	if err := t.getJSON(t.restURL("/buildTypes/id:%s/triggers", buildTypeID), &triggers); err != nil {
+		return nil, err
+	}
+	return triggers.Trigger, nil
+}
+
+/*
+ValidateBuildRequest checks that a build can be triggered with
+StartBuild before adding it to the queue
+
+It fails if the build pipeline does not exist or if any of its
+required parameters, the ones with no value that are marked to be
+prompted or not empty, is not provided in params.
+*/
+func (t *TCClient) ValidateBuildRequest(buildTypeID, branch string, params map[string]string) error {
+	buildType, err := t.GetBuildType(buildTypeID)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("Build pipeline %s does not exist", buildTypeID)
+		}
+		return err
+	}
+
+	if buildType.Parameters == nil {
+		return nil
+	}
+
+	missing := []string{}
+	for _, param := range buildType.Parameters.Property {
+		if param.Value != "" || param.Type == nil || !requiredParameter(param.Type.RawValue) {
+			continue
+		}
+		if params[param.Name] == "" {
+			missing = append(missing, param.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("Build pipeline %s requires parameters %s to trigger a build on branch %q",
+			buildTypeID, strings.Join(missing, ", "), branch)
+	}
+	return nil
+}
+
+// requiredParameter reports whether the parameter type spec,
+// e.g. "text display='prompt' validationMode='not_empty'",
+// requires a value to be provided when triggering a build
+func requiredParameter(spec string) bool {
+	return strings.Contains(spec, "validationMode='not_empty'") || strings.Contains(spec, "display='prompt'")
+}
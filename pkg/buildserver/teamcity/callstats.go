@@ -0,0 +1,72 @@
+package teamcity
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CallStat describes a call made to the teamcity server
+type CallStat struct {
+	Method     string
+	Path       string
+	StatusCode int // 0 when no response was received
+	Duration   time.Duration
+}
+
+// callRecorder keeps the stats of the last calls in a ring buffer
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []CallStat
+	next  int
+	full  bool
+}
+
+// WithCallStats keeps the method, path, status code and duration of
+// the last size calls made by the client, returned by RecentCalls
+func WithCallStats(size int) TCClientOption {
+	return func(t *TCClient) {
+		if size > 0 {
+			t.calls = &callRecorder{calls: make([]CallStat, size)}
+		}
+	}
+}
+
+// record adds the outcome of a call to the ring buffer,
+// overwriting the oldest call once it is full
+func (r *callRecorder) record(req *http.Request, resp *http.Response, duration time.Duration) {
+	stat := CallStat{
+		Method:   req.Method,
+		Path:     req.URL.Path,
+		Duration: duration,
+	}
+	if resp != nil {
+		stat.StatusCode = resp.StatusCode
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls[r.next] = stat
+	r.next = (r.next + 1) % len(r.calls)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// RecentCalls returns the stats of the last calls made by the client,
+// oldest first. It is empty unless the client was created WithCallStats.
+func (t *TCClient) RecentCalls() []CallStat {
+	if t.calls == nil {
+		return nil
+	}
+
+	r := t.calls
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]CallStat{}, r.calls[:r.next]...)
+	}
+	return append(append([]CallStat{}, r.calls[r.next:]...), r.calls[:r.next]...)
+}
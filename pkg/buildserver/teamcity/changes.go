@@ -0,0 +1,42 @@
+package teamcity
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GetChangesSinceLastSuccessful returns the changes of a build pipeline
+// that came after the latest change built by its last successful build
+func (t *TCClient) GetChangesSinceLastSuccessful(buildTypeID string) ([]TCChange, error) {
+	builds, err := t.GetAllBuilds(TCQueryParams{
+		BuildTypeID: buildTypeID,
+		Status:      string(StatusSuccess),
+		Count:       1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(builds.Builds) == 0 {
+		return nil, fmt.Errorf("No successful build found for build pipeline %s", buildTypeID)
+	}
+
+	var lastSuccessful TCBuildDetails
+	err = t.GetBuildFields(builds.Builds[0].ID, "id,lastChanges(change(id))", &lastSuccessful)
+	if err != nil {
+		return nil, err
+	}
+	if lastSuccessful.LastChanges == nil || len(lastSuccessful.LastChanges.Change) == 0 {
+		return nil, fmt.Errorf("No changes recorded for last successful build %d of build pipeline %s",
+			lastSuccessful.ID, buildTypeID)
+	}
+
+	locator := NewLocator().
+		BuildType(buildTypeID).
+		Nested("sinceChange", NewLocator().Dimension("id", strconv.Itoa(lastSuccessful.LastChanges.Change[0].ID)))
+
+	var changes TCChanges
+	err = t.getJSON(t.restURL("/changes?locator=%s&fields=change(id,version,username,date,comment,webUrl)",
+		url.QueryEscape(locator.String())), &changes)
+	return changes.Change, err
+}
@@ -0,0 +1,33 @@
+package teamcity_test
+
+import (
+	"testing"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity/teamcitytest"
+)
+
+func TestBuildCommentRoundTrip(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	comment := "Release \"v1.2\" isn't 'final'\nSee https://ci.example.com/viewLog.html?buildId=1&tab=log#step <b>2</b>\n\tDone"
+
+	queued, err := client.StartBuildWithParams(teamcity.TCStartBuildParams{
+		BuildTypeID: "Pipeline",
+		Branch:      "main",
+		Comment:     comment,
+	})
+	if err != nil {
+		t.Fatalf("StartBuildWithParams() error = %v", err)
+	}
+
+	var build teamcity.TCBuildDetails
+	if err := client.GetBuild(queued.ID, &build); err != nil {
+		t.Fatalf("GetBuild() error = %v", err)
+	}
+	if build.Comment.Text != comment {
+		t.Errorf("GetBuild() comment = %q, want %q", build.Comment.Text, comment)
+	}
+}
@@ -0,0 +1,150 @@
+package teamcity
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ErrBuildNotFound is returned when teamcity responds with 404
+// to a request for a build or its artifacts
+var ErrBuildNotFound = errors.New("build not found")
+
+// APIError is returned when teamcity responds with a non 2xx status code
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Message    string // Error message sent by teamcity, decoded from the body
+	Body       string // Response body sent by teamcity, as is
+	Err        error  // Sentinel error the response maps to, if any
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s failed with status %s", e.Method, e.URL, e.Status)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns the sentinel error, so that errors.Is(err, ErrBuildNotFound) works
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// checkResponse returns an *APIError built from the response
+// if its status code is not 2xx, nil otherwise
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	return &APIError{
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.Redacted(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Message:    errorMessage(resp.Header.Get("Content-Type"), body),
+		Body:       string(body),
+	}
+}
+
+// exceptionPrefix matches the exception class teamcity
+// puts in front of the error messages, e.g.
+// "jetbrains.buildServer.server.rest.errors.BadRequestException: "
+var exceptionPrefix = regexp.MustCompile(`^([\w$]+\.)*[\w$]*Exception: `)
+
+/*
+errorMessage extracts the error message from an error response body
+
+teamcity sends errors as plain text, e.g.
+
+	Error has occurred during request processing (Bad Request).
+	Error: jetbrains.buildServer.server.rest.errors.BadRequestException: Responsible investigation required
+
+from which "Responsible investigation required" is returned. JSON and
+XML bodies, as sent by newer versions or by proxies, are reduced to
+their text content.
+*/
+func errorMessage(contentType string, body []byte) string {
+	body = bytes.TrimSpace(body)
+	switch {
+	case len(body) == 0:
+		return ""
+	case strings.Contains(contentType, "json"):
+		var errs struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if json.Unmarshal(body, &errs) == nil && len(errs.Errors) > 0 {
+			messages := make([]string, 0, len(errs.Errors))
+			for _, e := range errs.Errors {
+				messages = append(messages, e.Message)
+			}
+			return strings.Join(messages, "; ")
+		}
+	case strings.Contains(contentType, "xml") || strings.Contains(contentType, "html") || body[0] == '<':
+		if text := xmlText(body); text != "" {
+			return text
+		}
+	}
+
+	for _, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Error: ") {
+			return exceptionPrefix.ReplaceAllString(strings.TrimPrefix(line, "Error: "), "")
+		}
+	}
+	return strings.Join(strings.Fields(string(body)), " ")
+}
+
+// xmlText returns the text content of an XML or HTML document
+// with its whitespace collapsed, or "" if it cannot be parsed
+func xmlText(body []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	text := []string{}
+	skip := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ""
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			skip = t.Name.Local == "script" || t.Name.Local == "style"
+		case xml.EndElement:
+			skip = false
+		case xml.CharData:
+			if !skip {
+				text = append(text, strings.Fields(string(t))...)
+			}
+		}
+	}
+	return strings.Join(text, " ")
+}
+
+// buildNotFound maps a 404 APIError to ErrBuildNotFound
+func buildNotFound(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		apiErr.Err = ErrBuildNotFound
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package teamcity
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFormat is the layout of the timestamps used by teamcity
+const TimeFormat = "20060102T150405-0700"
+
+// locatorValue escapes a value used in a locator dimension.
+// Plain values are returned as is, values with locator syntax
+// characters are wrapped in parentheses, and values which can't be
+// wrapped because of unbalanced parentheses are base64 encoded.
+func locatorValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, ",:() \t") {
+		return value
+	}
+	if balancedParens(value) {
+		return "(" + value + ")"
+	}
+	return "($base64:" + base64.URLEncoding.EncodeToString([]byte(value)) + ")"
+}
+
+// balancedParens reports whether every parenthesis in the
+// value is closed in order
+func balancedParens(value string) bool {
+	depth := 0
+	for _, r := range value {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// Locator builds a teamcity locator, e.g.
+// buildType:(id:MyBuild),branch:(name:master),count:10
+// Dimension values are escaped. The chainable methods cover the
+// dimensions of the builds locator, Dimension and Nested can be used
+// for any other locator.
+type Locator struct {
+	dimensions []string
+}
+
+// NewLocator returns an empty locator
+func NewLocator() *Locator {
+	return &Locator{}
+}
+
+// Dimension adds a dimension with the escaped value
+func (l *Locator) Dimension(name, value string) *Locator {
+	return l.add(name, locatorValue(value))
+}
+
+// Nested adds a dimension whose value is another locator
+func (l *Locator) Nested(name string, nested *Locator) *Locator {
+	return l.add(name, "("+nested.String()+")")
+}
+
+// BuildType adds the buildType dimension for the build pipeline id
+func (l *Locator) BuildType(id string) *Locator {
+	return l.Nested("buildType", NewLocator().Dimension("id", id))
+}
+
+// Branch adds the branch dimension for the branch name
+func (l *Locator) Branch(name string) *Locator {
+	return l.Nested("branch", NewLocator().Dimension("name", name))
+}
+
+// AnyBranch adds the branch dimension matching the builds of all
+// branches, teamcity only matches the default branch otherwise
+func (l *Locator) AnyBranch() *Locator {
+	return l.Nested("branch", NewLocator().Dimension("default", "any"))
+}
+
+// Status adds the status dimension, e.g. StatusSuccess
+func (l *Locator) Status(status BuildStatus) *Locator {
+	return l.Dimension("status", string(status))
+}
+
+// State adds the state dimension, e.g. StateFinished
+func (l *Locator) State(state BuildState) *Locator {
+	return l.Dimension("state", string(state))
+}
+
+// User adds the user dimension for the teamcity username
+func (l *Locator) User(username string) *Locator {
+	return l.Dimension("user", username)
+}
+
+// Agent adds the agent dimension for the agent name
+func (l *Locator) Agent(name string) *Locator {
+	return l.Nested("agent", NewLocator().Dimension("name", name))
+}
+
+// Tag adds a tag dimension, every tag added must be present
+func (l *Locator) Tag(tag string) *Locator {
+	return l.Dimension("tag", tag)
+}
+
+// Revision adds the revision dimension for the builds that include
+// the revision of the VCS root, any VCS root if vcsRootID is empty
+func (l *Locator) Revision(vcsRootID, version string) *Locator {
+	revision := NewLocator().Dimension("version", version)
+	if vcsRootID != "" {
+		revision.Nested("vcsRoot", NewLocator().Dimension("id", vcsRootID))
+	}
+	return l.Nested("revision", revision)
+}
+
+// Running adds the running dimension
+func (l *Locator) Running(running bool) *Locator {
+	return l.add("running", strconv.FormatBool(running))
+}
+
+// Cancelled adds the canceled dimension
+func (l *Locator) Cancelled(cancelled bool) *Locator {
+	return l.add("canceled", strconv.FormatBool(cancelled))
+}
+
+// Pinned adds the pinned dimension
+func (l *Locator) Pinned(pinned bool) *Locator {
+	return l.add("pinned", strconv.FormatBool(pinned))
+}
+
+// Personal adds the personal dimension
+func (l *Locator) Personal(personal bool) *Locator {
+	return l.add("personal", strconv.FormatBool(personal))
+}
+
+// SinceDate adds the sinceDate dimension to find items after the time
+func (l *Locator) SinceDate(since time.Time) *Locator {
+	return l.add("sinceDate", since.Format(TimeFormat))
+}
+
+// Count adds the count dimension that limits the number of items returned
+func (l *Locator) Count(n int) *Locator {
+	return l.add("count", strconv.Itoa(n))
+}
+
+// Start adds the start dimension, the index of the first item returned
+func (l *Locator) Start(n int) *Locator {
+	return l.add("start", strconv.Itoa(n))
+}
+
+// LookupLimit adds the lookupLimit dimension that limits
+// the number of items teamcity processes
+func (l *Locator) LookupLimit(n int) *Locator {
+	return l.add("lookupLimit", strconv.Itoa(n))
+}
+
+// String returns the locator, dimensions are separated by commas
+func (l *Locator) String() string {
+	return strings.Join(l.dimensions, ",")
+}
+
+// clone returns a copy of the locator that can be extended
+// without changing the locator
+func (l *Locator) clone() *Locator {
+	return &Locator{dimensions: append([]string{}, l.dimensions...)}
+}
+
+func (l *Locator) add(name, value string) *Locator {
+	l.dimensions = append(l.dimensions, name+":"+value)
+	return l
+}
@@ -0,0 +1,106 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+/*
+MuteTest mutes a test so that its failures do not fail the builds
+
+buildTypeID is the unique ID of the build pipeline the mute is scoped to
+
+testName is the full name of the test as reported by teamcity
+
+comment is recorded as the reason of the mute
+*/
+func (t *TCClient) MuteTest(ctx context.Context, buildTypeID, testName, comment string) error {
+	payload := TCMute{
+		Assignment: TCMuteAssignment{
+			Text: comment,
+		},
+		Scope: TCMuteScope{
+			BuildTypes: &TCBuildTypes{
+				BuildType: []TCBuildType{{ID: buildTypeID}},
+			},
+		},
+		Target: TCMuteTarget{
+			Tests: &TCTests{
+				Test: []TCTest{{Name: testName}},
+			},
+		},
+		Resolution: TCMuteResolution{
+			Type: "manually",
+		},
+	}
+
+	return t.doJSON(ctx, "POST", t.restURL("/mutes"), payload, nil)
+}
+
+// UnmuteTest removes the mutes of a test that are scoped
+// to the provided build pipeline
+func (t *TCClient) UnmuteTest(ctx context.Context, buildTypeID, testName string) error {
+	var mutes TCMutes
+	locator := url.QueryEscape(NewLocator().Nested("test", NewLocator().Dimension("name", testName)).String())
+	if err := t.doJSON(ctx, "GET", t.restURL("/mutes?locator=%s", locator), nil, &mutes); err != nil {
+		return err
+	}
+
+	found := false
+	for _, mute := range mutes.Mute {
+		if !mute.scopedTo(buildTypeID) {
+			continue
+		}
+		found = true
+		if err := t.doJSON(ctx, "DELETE", t.restURL("/mutes/id:%d", mute.ID), nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("No mute found for test %s in build pipeline %s", testName, buildTypeID)
+	}
+	return nil
+}
+
+/*
+GetMutedTests returns the mutes that apply to the build pipeline,
+both the ones scoped to it and the ones scoped to its projects
+
+Each mute carries the muted tests in Target, who muted them and why
+in Assignment and until when in Resolution.
+*/
+func (t *TCClient) GetMutedTests(buildTypeID string) ([]TCMute, error) {
+	buildType, err := t.GetBuildType(buildTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mutes TCMutes
+	locator := url.QueryEscape(NewLocator().Nested("affectedProject", NewLocator().Dimension("id", buildType.ProjectID)).String())
+	if err := t.getJSON(t.restURL("/mutes?locator=%s", locator), &mutes); err != nil {
+		return nil, err
+	}
+
+	result := []TCMute{}
+	for _, mute := range mutes.Mute {
+		if mute.Scope.Project != nil || mute.scopedTo(buildTypeID) {
+			result = append(result, mute)
+		}
+	}
+	return result, nil
+}
+
+// scopedTo reports whether the mute applies to the build pipeline
+func (m TCMute) scopedTo(buildTypeID string) bool {
+	if m.Scope.BuildTypes == nil {
+		return false
+	}
+	for _, buildType := range m.Scope.BuildTypes.BuildType {
+		if buildType.ID == buildTypeID {
+			return true
+		}
+	}
+	return false
+}
@@ -1,24 +1,182 @@
 package teamcity
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BuildState is the state of a build in its lifecycle
+type BuildState string
+
+// Build states reported by teamcity
+const (
+	StateQueued   BuildState = "queued"
+	StateRunning  BuildState = "running"
+	StateFinished BuildState = "finished"
+)
+
+// BuildStatus is the result of a build
+type BuildStatus string
+
+// Build statuses reported by teamcity
+const (
+	StatusSuccess BuildStatus = "SUCCESS"
+	StatusFailure BuildStatus = "FAILURE"
+	StatusError   BuildStatus = "ERROR"
+	StatusUnknown BuildStatus = "UNKNOWN"
+)
+
 // TCBuildType ...
 type TCBuildType struct {
-	ID          string `json:"id"`
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	ProjectName string `json:"projectName,omitempty"`
-	ProjectID   string `json:"projectId,omitempty"`
-	WebURL      string `json:"webUrl,omitempty"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name,omitempty"`
+	Description string             `json:"description,omitempty"`
+	ProjectName string             `json:"projectName,omitempty"`
+	ProjectID   string             `json:"projectId,omitempty"`
+	Href        string             `json:"href,omitempty"`
+	WebURL      string             `json:"webUrl,omitempty"`
+	Parameters  *TCBuildProperties `json:"parameters,omitempty"`
+}
+
+// TCProject ...
+type TCProject struct {
+	ID              string        `json:"id"`
+	Name            string        `json:"name,omitempty"`
+	ParentProjectID string        `json:"parentProjectId,omitempty"`
+	WebURL          string        `json:"webUrl,omitempty"`
+	Projects        *TCProjects   `json:"projects,omitempty"`
+	BuildTypes      *TCBuildTypes `json:"buildTypes,omitempty"`
+}
+
+// TCProjects ...
+type TCProjects struct {
+	Count   int         `json:"count,omitempty"`
+	Project []TCProject `json:"project"`
+}
+
+// TCProjectNode is a project with its nested sub-projects and build pipelines
+type TCProjectNode struct {
+	ID         string
+	Name       string
+	WebURL     string
+	BuildTypes []TCBuildType
+	Children   []*TCProjectNode
+}
+
+// TCAgentPool ...
+type TCAgentPool struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// TCAgent ...
+type TCAgent struct {
+	ID         int          `json:"id,omitempty"`
+	Name       string       `json:"name,omitempty"`
+	TypeID     int          `json:"typeId,omitempty"`
+	Connected  bool         `json:"connected,omitempty"`
+	Enabled    bool         `json:"enabled,omitempty"`
+	Authorized bool         `json:"authorized,omitempty"`
+	Href       string       `json:"href,omitempty"`
+	WebURL     string       `json:"webUrl,omitempty"`
+	Pool       *TCAgentPool `json:"pool,omitempty"`
+}
+
+// TCAgents ...
+type TCAgents struct {
+	Count int       `json:"count,omitempty"`
+	Agent []TCAgent `json:"agent"`
+}
+
+// TCTrigger ...
+type TCTrigger struct {
+	ID         string             `json:"id,omitempty"`
+	Type       string             `json:"type"` // e.g. "vcsTrigger", "schedulingTrigger" or "buildDependencyTrigger"
+	Disabled   bool               `json:"disabled,omitempty"`
+	Inherited  bool               `json:"inherited,omitempty"` // Defined in the template of the build pipeline
+	Properties *TCBuildProperties `json:"properties,omitempty"`
+}
+
+// TCTriggers ...
+type TCTriggers struct {
+	Count   int         `json:"count,omitempty"`
+	Trigger []TCTrigger `json:"trigger"`
 }
 
 // TCBuildComment ...
 type TCBuildComment struct {
-	Text string `json:"text"`
+	Text      string  `json:"text"`
+	Timestamp string  `json:"timestamp,omitempty"`
+	User      *TCUser `json:"user,omitempty"`
+}
+
+// TCPinInfo ...
+type TCPinInfo struct {
+	Status    bool    `json:"status"`
+	Text      string  `json:"text,omitempty"`
+	Timestamp string  `json:"timestamp,omitempty"`
+	User      *TCUser `json:"user,omitempty"`
+}
+
+// TCCanceledInfo ...
+type TCCanceledInfo struct {
+	Text      string  `json:"text,omitempty"`
+	Timestamp string  `json:"timestamp,omitempty"`
+	User      *TCUser `json:"user,omitempty"`
+}
+
+// TCAuditEntry ...
+type TCAuditEntry struct {
+	Action    string  // "comment" or "pin"
+	Text      string  // Comment or reason recorded with the action
+	Timestamp string  // Time of the action in teamcity format, e.g. 20200101T120000+0000
+	User      *TCUser // User who performed the action
+}
+
+// TCParameterType ...
+type TCParameterType struct {
+	RawValue string `json:"rawValue"`
 }
 
 // TCBuildProperty ...
 type TCBuildProperty struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Name  string           `json:"name"`
+	Value string           `json:"value"`
+	Type  *TCParameterType `json:"type,omitempty"`
+}
+
+// ParameterKind decides the prefix of a build parameter name
+type ParameterKind string
+
+// Kinds of build parameters
+const (
+	ParameterConfig ParameterKind = ""        // configuration parameter, not prefixed
+	ParameterSystem ParameterKind = "system." // passed to the build tool as a system property
+	ParameterEnv    ParameterKind = "env."    // passed to the build as an environment variable
+)
+
+// TCBuildParameter is a build parameter whose name is
+// prefixed as per its kind when the build is triggered
+type TCBuildParameter struct {
+	Name     string        // Parameter name without the kind prefix
+	Value    string        // Parameter value
+	Kind     ParameterKind // Config, system or env parameter
+	Password bool          // Value is hidden by teamcity in logs and UI
+}
+
+// FullName returns the parameter name prefixed as per its kind
+func (p TCBuildParameter) FullName() string {
+	return string(p.Kind) + strings.TrimPrefix(p.Name, string(p.Kind))
+}
+
+// property returns the build property entry of the parameter
+func (p TCBuildParameter) property() TCBuildProperty {
+	property := TCBuildProperty{Name: p.FullName(), Value: p.Value}
+	if p.Password {
+		property.Type = &TCParameterType{RawValue: "password"}
+	}
+	return property
 }
 
 // TCBuildProperties ...
@@ -29,8 +187,17 @@ type TCBuildProperties struct {
 
 // TCBuildSnapshotDependencies ...
 type TCBuildSnapshotDependencies struct {
-	Count  int              `json:"count,omitempty"`
-	Builds []TCBuildDetails `json:"build,omitempty"`
+	Count    int              `json:"count,omitempty"`
+	Href     string           `json:"href,omitempty"`
+	NextHref string           `json:"nextHref,omitempty"` // Next page of builds, empty on the last page
+	PrevHref string           `json:"prevHref,omitempty"`
+	Builds   []TCBuildDetails `json:"build,omitempty"`
+}
+
+// TCTriggeringOptions ...
+type TCTriggeringOptions struct {
+	RebuildAllDependencies bool          `json:"rebuildAllDependencies,omitempty"`
+	RebuildDependencies    *TCBuildTypes `json:"rebuildDependencies,omitempty"`
 }
 
 // TCBuildPayload ...
@@ -42,6 +209,48 @@ type TCBuildPayload struct {
 	BranchName           string                       `json:"branchName,omitempty"`
 	SnapshotDependencies *TCBuildSnapshotDependencies `json:"snapshot-dependencies,omitempty"`
 	ArtifactDependencies *TCBuildSnapshotDependencies `json:"artifact-dependencies,omitempty"`
+	TriggeringOptions    *TCTriggeringOptions         `json:"triggeringOptions,omitempty"`
+	Revisions            *TCRevisions                 `json:"revisions,omitempty"`
+	Agent                *TCAgent                     `json:"agent,omitempty"`
+}
+
+// TCVcsRootInstance ...
+type TCVcsRootInstance struct {
+	ID        string `json:"id,omitempty"`
+	VcsRootID string `json:"vcs-root-id,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// TCRevision ...
+type TCRevision struct {
+	Version         string             `json:"version"`
+	VcsBranchName   string             `json:"vcsBranchName,omitempty"`
+	VcsRootInstance *TCVcsRootInstance `json:"vcs-root-instance,omitempty"`
+}
+
+// TCRevisions ...
+type TCRevisions struct {
+	Count    int          `json:"count,omitempty"`
+	Revision []TCRevision `json:"revision"`
+}
+
+// TCTriggeredBy ...
+type TCTriggeredBy struct {
+	Type    string          `json:"type,omitempty"` // "user", "vcs", "unknown" or the trigger type
+	Details string          `json:"details,omitempty"`
+	Date    string          `json:"date,omitempty"`
+	User    *TCUser         `json:"user,omitempty"`
+	Build   *TCBuildDetails `json:"build,omitempty"` // Originating build, if triggered by another build
+}
+
+// TCRunningInfo ...
+type TCRunningInfo struct {
+	PercentageComplete    int    `json:"percentageComplete"`
+	ElapsedSeconds        int64  `json:"elapsedSeconds"`
+	EstimatedTotalSeconds int64  `json:"estimatedTotalSeconds"`
+	CurrentStageText      string `json:"currentStageText,omitempty"`
+	Outdated              bool   `json:"outdated"`
+	ProbablyHanging       bool   `json:"probablyHanging"`
 }
 
 // TCBuildDetails ...
@@ -49,16 +258,85 @@ type TCBuildDetails struct {
 	ID                   int                          `json:"id"`
 	BuildTypeID          string                       `json:"buildTypeId,omitempty"`
 	Number               string                       `json:"number,omitempty"`
-	Status               string                       `json:"status,omitempty"`
-	State                string                       `json:"state,omitempty"`
+	Status               BuildStatus                  `json:"status,omitempty"`
+	State                BuildState                   `json:"state,omitempty"`
 	BranchName           string                       `json:"branchName,omitempty"`
+	DefaultBranch        bool                         `json:"defaultBranch,omitempty"`
+	Personal             bool                         `json:"personal,omitempty"`
+	Composite            bool                         `json:"composite,omitempty"`
+	Customized           bool                         `json:"customized,omitempty"`
+	FailedToStart        bool                         `json:"failedToStart,omitempty"`
+	PercentageComplete   int                          `json:"percentageComplete,omitempty"` // Set while running
+	Href                 string                       `json:"href,omitempty"`
 	WebURL               string                       `json:"webUrl,omitempty"`
 	StatusText           string                       `json:"statusText,omitempty"`
+	QueuedDate           string                       `json:"queuedDate,omitempty"`
+	StartDate            string                       `json:"startDate,omitempty"`
+	FinishDate           string                       `json:"finishDate,omitempty"`
+	FinishOnAgentDate    string                       `json:"finishOnAgentDate,omitempty"`
 	Comment              TCBuildComment               `json:"comment,omitempty"`
 	BuildType            TCBuildType                  `json:"buildType,omitempty"`
 	Properties           TCBuildProperties            `json:"properties,omitempty"`
 	SnapshotDependencies *TCBuildSnapshotDependencies `json:"snapshot-dependencies,omitempty"`
 	ArtifactDependencies *TCBuildSnapshotDependencies `json:"artifact-dependencies,omitempty"`
+	Triggered            *TCTriggeredBy               `json:"triggered,omitempty"`
+	Revisions            *TCRevisions                 `json:"revisions,omitempty"`
+	RunningInfo          *TCRunningInfo               `json:"running-info,omitempty"`
+	Agent                *TCAgent                     `json:"agent,omitempty"` // Agent that ran the build, nil while queued
+	LastChanges          *TCChanges                   `json:"lastChanges,omitempty"`
+	PinInfo              *TCPinInfo                   `json:"pinInfo,omitempty"`
+	CanceledInfo         *TCCanceledInfo              `json:"canceledInfo,omitempty"` // Who cancelled the build and why, nil unless cancelled
+	WaitReason           string                       `json:"waitReason,omitempty"`
+	QueuedWaitReasons    *TCBuildProperties           `json:"queuedWaitReasons,omitempty"`
+}
+
+// TCWaitReason is a reason a queued build is waiting for
+type TCWaitReason struct {
+	Reason   string        // Human readable explanation, e.g. no compatible agents
+	Duration time.Duration // Time the build has been waiting for this reason
+}
+
+// WaitReasons returns all the reasons the queued build is waiting
+// for, the duration is 0 if teamcity did not report it
+func (b TCBuildDetails) WaitReasons() []TCWaitReason {
+	reasons := []TCWaitReason{}
+	if b.QueuedWaitReasons == nil {
+		if b.WaitReason != "" {
+			reasons = append(reasons, TCWaitReason{Reason: b.WaitReason})
+		}
+		return reasons
+	}
+
+	for _, property := range b.QueuedWaitReasons.Property {
+		reason := TCWaitReason{Reason: property.Name}
+		// Teamcity reports the time spent waiting in milliseconds
+		if ms, err := strconv.ParseInt(property.Value, 10, 64); err == nil {
+			reason.Duration = time.Duration(ms) * time.Millisecond
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons
+}
+
+// ETA estimates the time remaining for a running build to finish.
+// It uses the estimate of teamcity, or extrapolates the elapsed time
+// from the percentage complete when there is none. It is 0 if the
+// build is not running or nothing can be estimated.
+func (b TCBuildDetails) ETA() time.Duration {
+	info := b.RunningInfo
+	if info == nil {
+		return 0
+	}
+
+	total := info.EstimatedTotalSeconds
+	if total <= 0 && info.PercentageComplete > 0 {
+		total = info.ElapsedSeconds * 100 / int64(info.PercentageComplete)
+	}
+
+	if remaining := total - info.ElapsedSeconds; remaining > 0 {
+		return time.Duration(remaining) * time.Second
+	}
+	return 0
 }
 
 // TCBuildStopPayload ...
@@ -67,15 +345,276 @@ type TCBuildStopPayload struct {
 	ReaddIntoQueue string `json:"readdIntoQueue"` // "true" or "false"
 }
 
+// TCBuildStatusUpdate ...
+type TCBuildStatusUpdate struct {
+	Status  BuildStatus `json:"status"`
+	Comment string      `json:"comment,omitempty"`
+}
+
+// TCStopBuildParams ...
+type TCStopBuildParams struct {
+	ID      int    // ID of the running build
+	Comment string // Text comment recorded as the reason of the cancellation
+	// Change the status of the stopped build to failed so that it counts as
+	// a failure. teamcity has no such option when cancelling a build, the
+	// status is changed once the build is finished. The build still shows
+	// as cancelled, with the FAILURE status.
+	MarkAsFailed bool
+}
+
+// TCStartBuildParams ...
+type TCStartBuildParams struct {
+	BuildTypeID            string             // Pipeline name (BuildConfig ID)
+	Branch                 string             // Branch name
+	Comment                string             // Text comment on the build, sent as is, newlines and quotes need no escaping
+	Params                 map[string]string  // Parameters passed as is, names must carry their prefix
+	Parameters             []TCBuildParameter // Typed parameters, prefixed as per their kind
+	SnapshotDependencies   map[string]int     // Pipeline ID to the build ID reused as snapshot dependency
+	ArtifactDependencies   map[string]int     // Pipeline ID to the build ID whose artifacts are used
+	RebuildAllDependencies bool               // Rebuild all snapshot dependencies instead of reusing suitable builds
+	RebuildDependencies    []string           // Pipeline IDs of the snapshot dependencies to rebuild instead of reusing
+	Revisions              map[string]string  // VCS root ID to the revision the build is pinned to
+	Personal               bool               // Mark the build as personal build of the user the token belongs to
+	AgentID                int                // ID of the agent to run the build on, 0 lets teamcity pick a compatible agent
+}
+
 // TCQueryParams ...
 type TCQueryParams struct {
-	BuildTypeID string // Pipeline name (BuildConfig ID)
-	Branch      string // Branch name
-	Status      string // Status such as SUCCESS FAILURE UNKNOWN
-	User        string // Teamcity username
-	Running     bool   // Build running
-	Cancelled   bool   // Build cancelled
-	Start       uint   // Start index when listing builds
-	Count       uint   // Number of build records to return from start index
-	LookupLimit uint   // Lookup limit that limits teamcity to process the latest N builds only
+	BuildTypeID string   // Pipeline name (BuildConfig ID)
+	Branch      string   // Branch name
+	Status      string   // Status such as SUCCESS FAILURE UNKNOWN
+	User        string   // Teamcity username
+	Running     bool     // Build running
+	Cancelled   bool     // Build cancelled
+	Start       uint     // Start index when listing builds
+	Count       uint     // Number of build records to return from start index
+	LookupLimit uint     // Lookup limit that limits teamcity to process the latest N builds only
+	Pinned      *bool    // Build pinned, nil does not filter on pinned state
+	Tags        []string // Build tags, builds must have all of them
+	Personal    *bool    // Personal build, nil does not filter on personal builds
+	Agent       string   // Name of the agent the build ran on
+	AllBranches bool     // Builds of all branches, teamcity only returns the builds of the default branch otherwise
+	Fields      string   // Fields of the response, e.g. "count,build(id,status)", used by GetBuilds only
+}
+
+// TCUser ...
+type TCUser struct {
+	ID       int    `json:"id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// TCBuildTypes ...
+type TCBuildTypes struct {
+	Count     int           `json:"count,omitempty"`
+	BuildType []TCBuildType `json:"buildType"`
+}
+
+// TCTest ...
+type TCTest struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// TCTests ...
+type TCTests struct {
+	Count int      `json:"count,omitempty"`
+	Test  []TCTest `json:"test"`
+}
+
+// TCMuteAssignment ...
+type TCMuteAssignment struct {
+	Text      string  `json:"text,omitempty"`
+	Timestamp string  `json:"timestamp,omitempty"`
+	User      *TCUser `json:"user,omitempty"`
+}
+
+// TCMuteScope ...
+type TCMuteScope struct {
+	Project    *TCProject    `json:"project,omitempty"`
+	BuildTypes *TCBuildTypes `json:"buildTypes,omitempty"`
+}
+
+// TCMuteTarget ...
+type TCMuteTarget struct {
+	Tests *TCTests `json:"tests,omitempty"`
+}
+
+// TCMuteResolution ...
+type TCMuteResolution struct {
+	Type string `json:"type"` // "manually", "whenFixed" or "atTime"
+	Time string `json:"time,omitempty"`
+}
+
+// TCMute ...
+type TCMute struct {
+	ID         int              `json:"id,omitempty"`
+	Assignment TCMuteAssignment `json:"assignment"`
+	Scope      TCMuteScope      `json:"scope"`
+	Target     TCMuteTarget     `json:"target"`
+	Resolution TCMuteResolution `json:"resolution"`
+}
+
+// TCMutes ...
+type TCMutes struct {
+	Count int      `json:"count,omitempty"`
+	Mute  []TCMute `json:"mute"`
+}
+
+// TCUsers ...
+type TCUsers struct {
+	Count int      `json:"count,omitempty"`
+	User  []TCUser `json:"user"`
+}
+
+// TCGroup ...
+type TCGroup struct {
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// TCUserApproval ...
+type TCUserApproval struct {
+	User     TCUser `json:"user"`
+	Approved bool   `json:"approved"`
+}
+
+// TCUserApprovals ...
+type TCUserApprovals struct {
+	UserApproval []TCUserApproval `json:"userApproval"`
+}
+
+// TCGroupApproval ...
+type TCGroupApproval struct {
+	Group                  TCGroup `json:"group"`
+	RequiredApprovalsCount int     `json:"requiredApprovalsCount"`
+	CurrentlyApprovedBy    TCUsers `json:"currentlyApprovedBy"`
+}
+
+// TCGroupApprovals ...
+type TCGroupApprovals struct {
+	GroupApproval []TCGroupApproval `json:"groupApproval"`
+}
+
+// TCApprovalInfo ...
+type TCApprovalInfo struct {
+	Status                     string           `json:"status"` // "waitingForApproval", "approved" or "timedOut"
+	TimeoutTimestamp           string           `json:"timeoutTimestamp,omitempty"`
+	CanBeApprovedByCurrentUser bool             `json:"canBeApprovedByCurrentUser"`
+	ConfigurationValid         bool             `json:"configurationValid"`
+	UserApprovals              TCUserApprovals  `json:"userApprovals"`
+	GroupApprovals             TCGroupApprovals `json:"groupApprovals"`
+}
+
+// TCArtifactMeta ...
+type TCArtifactMeta struct {
+	Name             string  `json:"name"`
+	FullName         string  `json:"fullName,omitempty"`
+	Size             int64   `json:"size"`
+	ModificationTime string  `json:"modificationTime,omitempty"`
+	Href             string  `json:"href,omitempty"`
+	Content          *TCHref `json:"content,omitempty"`  // Set for files, including archives
+	Children         *TCHref `json:"children,omitempty"` // Set for directories and archives
+}
+
+// TCHref ...
+type TCHref struct {
+	Href string `json:"href,omitempty"`
+}
+
+// TCArtifactFiles ...
+type TCArtifactFiles struct {
+	Count int              `json:"count,omitempty"`
+	File  []TCArtifactMeta `json:"file"`
+}
+
+// TCChange ...
+type TCChange struct {
+	ID       int    `json:"id"`
+	Version  string `json:"version,omitempty"`
+	Username string `json:"username,omitempty"`
+	Date     string `json:"date,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	WebURL   string `json:"webUrl,omitempty"`
+}
+
+// TCChanges ...
+type TCChanges struct {
+	Count  int        `json:"count,omitempty"`
+	Change []TCChange `json:"change"`
+}
+
+// TCAgentStatusInfo ...
+type TCAgentStatusInfo struct {
+	Status  bool           `json:"status"`
+	Comment TCBuildComment `json:"comment"`
+}
+
+// TCTestOccurrence ...
+type TCTestOccurrence struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Status         string          `json:"status,omitempty"` // "SUCCESS", "FAILURE" or "UNKNOWN"
+	Duration       int64           `json:"duration,omitempty"`
+	Details        string          `json:"details,omitempty"` // Failure message and stack trace
+	Ignored        bool            `json:"ignored,omitempty"`
+	Muted          bool            `json:"muted,omitempty"`
+	CurrentlyMuted bool            `json:"currentlyMuted,omitempty"`
+	NewFailure     bool            `json:"newFailure,omitempty"`
+	Href           string          `json:"href,omitempty"`
+	Build          *TCBuildDetails `json:"build,omitempty"`
+	Test           *TCTest         `json:"test,omitempty"`
+}
+
+// TCTestOccurrences ...
+type TCTestOccurrences struct {
+	Count          int                `json:"count,omitempty"`
+	TestOccurrence []TCTestOccurrence `json:"testOccurrence"`
+}
+
+// TCCoverage holds the code coverage percentages reported by a build,
+// a percentage the build did not report is 0
+type TCCoverage struct {
+	Statement float64 // CodeCoverageS
+	Line      float64 // CodeCoverageL
+	Branch    float64 // CodeCoverageR
+	Method    float64 // CodeCoverageM
+	Class     float64 // CodeCoverageC
+	Block     float64 // CodeCoverageB
+}
+
+// TCDurationStats holds statistics on the durations of builds
+type TCDurationStats struct {
+	Count int // Number of builds the statistics are computed from
+	Min   time.Duration
+	Max   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+}
+
+// TCProblemOccurrence ...
+type TCProblemOccurrence struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type,omitempty"`     // e.g. "TC_COMPILATION_ERROR" or "TC_EXIT_CODE"
+	Identity string          `json:"identity,omitempty"` // Identifies the same problem across builds
+	Details  string          `json:"details,omitempty"`
+	Href     string          `json:"href,omitempty"`
+	Build    *TCBuildDetails `json:"build,omitempty"`
+}
+
+// TCProblemOccurrences ...
+type TCProblemOccurrences struct {
+	Count             int                   `json:"count,omitempty"`
+	ProblemOccurrence []TCProblemOccurrence `json:"problemOccurrence"`
+}
+
+// TCLogMessage is a message of the build log
+type TCLogMessage struct {
+	ID        int    `json:"id"`                 // Position of the message in the log, starting at 1
+	ParentID  int    `json:"parentId,omitempty"` // ID of the block the message is nested in, 0 at the top level
+	Level     int    `json:"level"`              // Nesting level of the message in the log blocks
+	Text      string `json:"text"`
+	Timestamp string `json:"timestamp,omitempty"` // Server time of the day, e.g. "15:04:05"
+	Status    int    `json:"status,omitempty"`    // 1 for normal, 2 for warning, 4 for error messages
 }
@@ -0,0 +1,144 @@
+package teamcity
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TCClientOption configures optional settings of the TCClient
+// at the time of its creation
+type TCClientOption func(*TCClient)
+
+// WithRootCAs sets the certificate pool used to verify the certificate
+// presented by the teamcity server. Use it when the server certificate
+// is signed by an internal CA that is not part of the system trust store.
+func WithRootCAs(pool *x509.CertPool) TCClientOption {
+	return func(t *TCClient) {
+		if t.transport == nil {
+			t.optionErr = errNoTransport("WithRootCAs")
+			return
+		}
+		if t.transport.TLSClientConfig == nil {
+			t.transport.TLSClientConfig = &tls.Config{}
+		}
+		t.transport.TLSClientConfig.RootCAs = pool
+	}
+}
+
+// WithConnectionPool tunes the idle connections kept by the transport
+// for reuse. Raise maxIdleConnsPerHost, which defaults to 2, when many
+// goroutines share the client to talk to the same teamcity server.
+func WithConnectionPool(maxIdleConns, maxIdleConnsPerHost int, idleConnTimeout time.Duration) TCClientOption {
+	return func(t *TCClient) {
+		if t.transport == nil {
+			t.optionErr = errNoTransport("WithConnectionPool")
+			return
+		}
+		t.transport.MaxIdleConns = maxIdleConns
+		t.transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+		t.transport.IdleConnTimeout = idleConnTimeout
+	}
+}
+
+// WithHTTPClient replaces the http.Client built by the constructor
+// with a copy of a pre-built one, the timeouts and insecure flag passed
+// to the constructor are ignored in this case. Options that tune the
+// transport must come after this option and require the client to have
+// an *http.Transport, otherwise every call of the client fails. The
+// transport is shared with the pre-built client, so these options tune
+// it for both. The redirect policy stripping the token on redirects to
+// other hosts is installed on the copy unless the client has its own
+// CheckRedirect, the pre-built client is left untouched.
+func WithHTTPClient(client *http.Client) TCClientOption {
+	return func(t *TCClient) {
+		c := *client
+		t.client = &c
+		t.transport = nil
+		if tr, ok := c.Transport.(*http.Transport); ok {
+			t.transport = tr
+		}
+		if c.CheckRedirect == nil {
+			c.CheckRedirect = checkRedirect
+		}
+	}
+}
+
+// errNoTransport is the error of an option that tunes the transport
+// when the http client does not have an *http.Transport
+func errNoTransport(option string) error {
+	return fmt.Errorf("%s requires the http client to use an *http.Transport", option)
+}
+
+// WithTokenProvider makes the client call provider for the token
+// of every request instead of using the token passed to the
+// constructor, so that expiring tokens can be refreshed
+func WithTokenProvider(provider func() (string, error)) TCClientOption {
+	return func(t *TCClient) {
+		t.tokenProvider = provider
+	}
+}
+
+// WithLogger sets the logger the client writes its logs to,
+// the standard logger is used by default
+func WithLogger(logger *log.Logger) TCClientOption {
+	return func(t *TCClient) {
+		t.logger = logger
+	}
+}
+
+// WithRedactBodies controls whether the request and response bodies
+// are left out of the logs, only the method, URL and status being logged.
+// Bodies are redacted by default as build parameters may hold secrets.
+func WithRedactBodies(redact bool) TCClientOption {
+	return func(t *TCClient) {
+		t.logBodies = !redact
+	}
+}
+
+// WithRequestIDs sends a request id with every request in the provided
+// header, DefaultRequestIDHeader if empty. The id is taken from the
+// context set with ContextWithRequestID or generated for each request,
+// and logged along with the response status.
+func WithRequestIDs(header string) TCClientOption {
+	return func(t *TCClient) {
+		if header == "" {
+			header = DefaultRequestIDHeader
+		}
+		t.requestIDHeader = header
+	}
+}
+
+// WithPollInterval sets the interval between two requests when
+// the client polls a build, DefaultPollInterval is used by default
+func WithPollInterval(interval time.Duration) TCClientOption {
+	return func(t *TCClient) {
+		if interval > 0 {
+			t.pollInterval = interval
+		}
+	}
+}
+
+// WithAPIVersion pins the REST API version, e.g. "2018.1", so that
+// requests are made against /app/rest/<version>/... and the responses
+// keep the representation of that version across server upgrades.
+// The unversioned REST API is used by default.
+func WithAPIVersion(version string) TCClientOption {
+	return func(t *TCClient) {
+		t.apiVersion = strings.Trim(version, "/")
+	}
+}
+
+// WithStrictDecoding makes the client fail to decode responses holding
+// fields that are not part of the response types, to catch changes of
+// the response shape across teamcity upgrades. Unknown fields are
+// ignored by default.
+func WithStrictDecoding(strict bool) TCClientOption {
+	return func(t *TCClient) {
+		t.strictDecoding = strict
+	}
+}
@@ -0,0 +1,30 @@
+package teamcity_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity/teamcitytest"
+)
+
+func TestWithHTTPClient(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	server.AddBuild(teamcity.TCBuildDetails{ID: 1, BuildTypeID: "Pipeline", State: teamcity.StateFinished})
+
+	httpClient := &http.Client{Transport: &http.Transport{}}
+	client := server.Client(teamcity.WithHTTPClient(httpClient))
+
+	if httpClient.CheckRedirect != nil {
+		t.Errorf("WithHTTPClient() changed the redirect policy of the http client")
+	}
+	if got := client.HTTPClient(); got == httpClient || got.CheckRedirect == nil {
+		t.Errorf("HTTPClient() = %p, want a copy of %p with a redirect policy", got, httpClient)
+	}
+
+	var build teamcity.TCBuildDetails
+	if err := client.GetBuild(1, &build); err != nil {
+		t.Fatalf("GetBuild() error = %v", err)
+	}
+}
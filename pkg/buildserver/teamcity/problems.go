@@ -0,0 +1,40 @@
+package teamcity
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// GetRecurringProblems returns the build problems of the last count
+// finished builds of the build pipeline, as the number of builds
+// each problem occurred in keyed by the problem identity
+func (t *TCClient) GetRecurringProblems(buildTypeID string, count int) (map[string]int, error) {
+	locator := NewLocator().BuildType(buildTypeID).State(StateFinished).Count(count)
+	var builds TCBuildSnapshotDependencies
+	err := t.getJSON(t.restURL("/builds/?locator=%s&fields=build(id)", url.QueryEscape(locator.String())), &builds)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := map[string]int{}
+	for _, build := range builds.Builds {
+		locator := NewLocator().Nested("build", NewLocator().Dimension("id", strconv.Itoa(build.ID)))
+		var occurrences TCProblemOccurrences
+		err := t.getJSON(t.restURL("/problemOccurrences?locator=%s&fields=problemOccurrence(identity)",
+			url.QueryEscape(locator.String())), &occurrences)
+		if err != nil {
+			return nil, err
+		}
+
+		// Count a problem once per build
+		seen := map[string]bool{}
+		for _, occurrence := range occurrences.ProblemOccurrence {
+			if seen[occurrence.Identity] {
+				continue
+			}
+			seen[occurrence.Identity] = true
+			problems[occurrence.Identity]++
+		}
+	}
+	return problems, nil
+}
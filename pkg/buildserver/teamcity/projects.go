@@ -0,0 +1,56 @@
+package teamcity
+
+import "fmt"
+
+// RootProjectID is the ID of the TeamCity root project
+const RootProjectID = "_Root"
+
+// maxProjectDepth bounds the recursion of GetProjectTree
+const maxProjectDepth = 64
+
+// GetProjectTree returns the project with all its nested sub-projects
+// and build pipelines. rootProjectID defaults to the root project.
+func (t *TCClient) GetProjectTree(rootProjectID string) (*TCProjectNode, error) {
+	if rootProjectID == "" {
+		rootProjectID = RootProjectID
+	}
+	return t.getProjectNode(rootProjectID, 0, map[string]bool{})
+}
+
+func (t *TCClient) getProjectNode(projectID string, depth int, visited map[string]bool) (*TCProjectNode, error) {
+	if depth > maxProjectDepth {
+		return nil, fmt.Errorf("Project hierarchy under %s is deeper than %d levels", projectID, maxProjectDepth)
+	}
+	if visited[projectID] {
+		return nil, fmt.Errorf("Project %s appears twice in the project hierarchy", projectID)
+	}
+	visited[projectID] = true
+
+	var project TCProject
+	err := t.getJSON(t.restURL("/projects/id:%s?fields=id,name,webUrl,projects(project(id)),"+
+		"buildTypes(buildType(id,name,description,projectName,projectId,webUrl))", projectID), &project)
+	if err != nil {
+		return nil, err
+	}
+
+	node := &TCProjectNode{
+		ID:     project.ID,
+		Name:   project.Name,
+		WebURL: project.WebURL,
+	}
+	if project.BuildTypes != nil {
+		node.BuildTypes = project.BuildTypes.BuildType
+	}
+	if project.Projects == nil {
+		return node, nil
+	}
+
+	for _, child := range project.Projects.Project {
+		childNode, err := t.getProjectNode(child.ID, depth+1, visited)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
@@ -0,0 +1,47 @@
+package teamcity
+
+// GetResultingProperties returns the parameters a build actually ran
+// with, after all the references are resolved, as a map of name to value
+func (t *TCClient) GetResultingProperties(id int) (map[string]string, error) {
+	var properties TCBuildProperties
+	if err := t.getJSON(t.restURL("/builds/id:%d/resulting-properties", id), &properties); err != nil {
+		return nil, buildNotFound(err)
+	}
+
+	result := map[string]string{}
+	for _, property := range properties.Property {
+		result[property.Name] = property.Value
+	}
+	return result, nil
+}
+
+/*
+DiffBuildProperties compares the resulting properties of two builds
+
+It returns the properties whose value differs, keyed by name, along
+with their value in buildA and buildB. A property missing from one of
+the builds has an empty value on that side.
+*/
+func (t *TCClient) DiffBuildProperties(buildA, buildB int) (map[string][2]string, error) {
+	propertiesA, err := t.GetResultingProperties(buildA)
+	if err != nil {
+		return nil, err
+	}
+	propertiesB, err := t.GetResultingProperties(buildB)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := map[string][2]string{}
+	for name, valueA := range propertiesA {
+		if valueB, ok := propertiesB[name]; !ok || valueA != valueB {
+			diff[name] = [2]string{valueA, valueB}
+		}
+	}
+	for name, valueB := range propertiesB {
+		if _, ok := propertiesA[name]; !ok {
+			diff[name] = [2]string{"", valueB}
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,187 @@
+package teamcity
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GetBuildApprovalInfo returns the approval status of a queued build
+// that has the build approval feature enabled, along with the groups
+// whose approval is required and the users who have already approved
+func (t *TCClient) GetBuildApprovalInfo(id int) (TCApprovalInfo, error) {
+	var info TCApprovalInfo
+	err := t.getJSON(t.restURL("/buildQueue/id:%d/approvalInfo", id), &info)
+	return info, err
+}
+
+// ApproveQueuedBuild approves a queued build on behalf of
+// the user the token belongs to
+func (t *TCClient) ApproveQueuedBuild(id int) error {
+	return t.doJSON(context.Background(), "POST", t.restURL("/buildQueue/id:%d/approve", id), nil, nil)
+}
+
+// GetQueueSummary returns the number of queued builds per
+// build pipeline ID. Only the build pipeline ID of the queued
+// builds is requested to keep the response small.
+func (t *TCClient) GetQueueSummary() (map[string]int, error) {
+	var queue TCBuildSnapshotDependencies
+	if err := t.getJSON(t.restURL("/buildQueue?fields=build(buildTypeId)"), &queue); err != nil {
+		return nil, err
+	}
+
+	summary := map[string]int{}
+	for _, build := range queue.Builds {
+		summary[build.BuildTypeID]++
+	}
+	return summary, nil
+}
+
+// CancelQueuedBuildByBranch cancels the queued build of a build pipeline
+// on the provided branch and returns its id. It returns ErrBuildNotFound
+// if no such build is queued, the returned id is -1 on error.
+func (t *TCClient) CancelQueuedBuildByBranch(buildTypeID, branch, comment string) (int, error) {
+	locator := NewLocator().BuildType(buildTypeID)
+
+	var queue TCBuildSnapshotDependencies
+	err := t.getJSON(t.restURL("/buildQueue?locator=%s&fields=build(id,buildTypeId,branchName)",
+		url.QueryEscape(locator.String())), &queue)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, build := range queue.Builds {
+		if build.BranchName != branch {
+			continue
+		}
+		if err := t.CancelQueuedBuild(build.ID, comment); err != nil {
+			return -1, err
+		}
+		return build.ID, nil
+	}
+
+	return -1, fmt.Errorf("No queued build of %s on branch %s: %w", buildTypeID, branch, ErrBuildNotFound)
+}
+
+// CancelQueuedBuildByHref cancels a queued build using the href
+// sent back by teamcity for it, e.g. by StartBuildWithParams
+func (t *TCClient) CancelQueuedBuildByHref(href, comment string) error {
+	requestURL, err := t.hrefURL(href)
+	if err != nil {
+		return err
+	}
+
+	payload := TCBuildStopPayload{
+		Comment:        comment,
+		ReaddIntoQueue: "false",
+	}
+	return buildNotFound(t.doJSON(context.Background(), "POST", requestURL, payload, nil))
+}
+
+// hrefURL returns the absolute URL of an href sent back by teamcity,
+// which is relative to the server URL and may include its context path.
+// Absolute hrefs must point to the server so that the token is not sent
+// to another host.
+func (t *TCClient) hrefURL(href string) (string, error) {
+	if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+		if !strings.HasPrefix(href, t.serverURL+"/") {
+			return "", fmt.Errorf("Href %s does not belong to teamcity server %s", href, t.serverURL)
+		}
+		return href, nil
+	}
+
+	if u, err := url.Parse(t.serverURL); err == nil && u.Path != "" && strings.HasPrefix(href, u.Path+"/") {
+		href = strings.TrimPrefix(href, u.Path)
+	}
+	if !strings.HasPrefix(href, "/") {
+		href = "/" + href
+	}
+	return t.serverURL + href, nil
+}
+
+// GetQueuedBuildStatus returns a queued build along with the reasons
+// it is waiting for, use WaitReasons on the result to read them all
+func (t *TCClient) GetQueuedBuildStatus(id int) (TCBuildDetails, error) {
+	var details TCBuildDetails
+	err := t.getJSON(t.restURL("/buildQueue/id:%d?fields=id,buildTypeId,state,branchName,href,webUrl,"+
+		"waitReason,queuedWaitReasons(property(name,value))", id), &details)
+	return details, buildNotFound(err)
+}
+
+// GetQueuePosition returns the position, starting at 1, of a build
+// in the build queue. It returns ErrBuildNotFound if the build is not
+// queued, e.g. because it has started in the meantime.
+func (t *TCClient) GetQueuePosition(id int) (int, error) {
+	var queue TCBuildSnapshotDependencies
+	if err := t.getJSON(t.restURL("/buildQueue?fields=build(id)"), &queue); err != nil {
+		return -1, err
+	}
+
+	for i, build := range queue.Builds {
+		if build.ID == id {
+			return i + 1, nil
+		}
+	}
+	return -1, fmt.Errorf("Build %d is not queued: %w", id, ErrBuildNotFound)
+}
+
+// estimateHistorySize is the number of recent builds
+// averaged to estimate the duration of a build
+const estimateHistorySize = 10
+
+/*
+EstimateStartTime roughly estimates how long a newly queued build
+of the build pipeline would wait before starting
+
+It is computed as the number of queued builds times the average
+duration of the recent builds of the pipeline, divided by the number
+of agents compatible with the pipeline.
+*/
+func (t *TCClient) EstimateStartTime(buildTypeID string) (time.Duration, error) {
+	var queue TCBuildSnapshotDependencies
+	if err := t.getJSON(t.restURL("/buildQueue?fields=count"), &queue); err != nil {
+		return 0, err
+	}
+	if queue.Count == 0 {
+		return 0, nil
+	}
+
+	agents, err := t.GetCompatibleAgents(buildTypeID)
+	if err != nil {
+		return 0, err
+	}
+	if len(agents) == 0 {
+		return 0, fmt.Errorf("No compatible agents for build pipeline %s", buildTypeID)
+	}
+
+	locator := NewLocator().BuildType(buildTypeID).State(StateFinished).Count(estimateHistorySize)
+	var builds TCBuildSnapshotDependencies
+	err = t.getJSON(t.restURL("/builds/?locator=%s&fields=build(startDate,finishDate)",
+		url.QueryEscape(locator.String())), &builds)
+	if err != nil {
+		return 0, err
+	}
+
+	var total time.Duration
+	measured := 0
+	for _, build := range builds.Builds {
+		start, err := time.Parse(TimeFormat, build.StartDate)
+		if err != nil {
+			continue
+		}
+		finish, err := time.Parse(TimeFormat, build.FinishDate)
+		if err != nil {
+			continue
+		}
+		total += finish.Sub(start)
+		measured++
+	}
+	if measured == 0 {
+		return 0, fmt.Errorf("No finished builds of build pipeline %s to estimate from", buildTypeID)
+	}
+
+	average := total / time.Duration(measured)
+	return time.Duration(queue.Count) * average / time.Duration(len(agents)), nil
+}
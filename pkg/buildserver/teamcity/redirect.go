@@ -0,0 +1,39 @@
+package teamcity
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// maxRedirects is the number of redirects followed
+// before a request fails, same as the http package
+const maxRedirects = 10
+
+// checkRedirect is the redirect policy of the http client built by the
+// constructors, or injected WithHTTPClient without a policy of its own.
+// The Authorization header is dropped when a redirect leaves the
+// teamcity host, e.g. to an artifact storage, or downgrades to plain
+// http, so that the token is not sent to a third party. The http
+// package only drops it for hosts outside of the original domain, not
+// for its subdomains.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	original := via[0].URL
+	if !strings.EqualFold(req.URL.Host, original.Host) || req.URL.Scheme != original.Scheme {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
+
+// WithCheckRedirect sets the redirect policy of the http client,
+// replacing the one stripping the token on redirects to other hosts.
+// It must come after WithHTTPClient if both are used.
+func WithCheckRedirect(fn func(req *http.Request, via []*http.Request) error) TCClientOption {
+	return func(t *TCClient) {
+		t.client.CheckRedirect = fn
+	}
+}
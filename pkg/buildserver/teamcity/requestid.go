@@ -0,0 +1,56 @@
+package teamcity
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header the request id is sent in
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request id,
+// calls made with the returned context send it to teamcity so that they
+// can be correlated with the server logs
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request id carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// setRequestID adds the request id header to the request and returns
+// the id. The id is taken from the request context, or generated if
+// request ids are enabled on the client. It returns an empty string
+// if the request carries no id.
+func (t *TCClient) setRequestID(req *http.Request) string {
+	requestID := RequestIDFromContext(req.Context())
+	if requestID == "" && t.requestIDHeader != "" {
+		requestID = newRequestID()
+	}
+	if requestID == "" {
+		return ""
+	}
+
+	header := t.requestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	req.Header.Set(header, requestID)
+	return requestID
+}
+
+// newRequestID returns a random 128 bit hex encoded id
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,164 @@
+package teamcity
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retryPolicy decides whether and when a failed request is retried
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// WithRetry retries requests that failed with a network error or with
+// one of the 429, 502, 503 and 504 status codes, up to maxRetries times.
+// Only idempotent requests are retried, so adding a build to the queue
+// is never retried. The delay before retry n is a random duration
+// between 0 and baseDelay*2^n, capped at maxDelay, so that many clients
+// don't retry in lockstep. A maxDelay of 0 leaves the delay uncapped.
+// When a 429 or 503 response carries a Retry-After header, the delay
+// it asks for is used instead.
+func WithRetry(maxRetries int, baseDelay, maxDelay time.Duration) TCClientOption {
+	return func(t *TCClient) {
+		t.retry = &retryPolicy{
+			maxRetries: maxRetries,
+			baseDelay:  baseDelay,
+			maxDelay:   maxDelay,
+			rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		}
+	}
+}
+
+// backoff returns the full jitter delay before the retry
+// following the attempt, attempts are counted from 0
+func (p *retryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.maxDelay
+	if p.baseDelay > 0 {
+		// baseDelay*2^attempt, saturated instead of overflowing
+		d := time.Duration(math.MaxInt64)
+		if attempt < 63 && p.baseDelay <= d>>uint(attempt) {
+			d = p.baseDelay << uint(attempt)
+		}
+		if ceiling <= 0 || d < ceiling {
+			ceiling = d
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	n := int64(ceiling)
+	if n < math.MaxInt64 {
+		n++
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Duration(p.rnd.Int63n(n))
+}
+
+// shouldRetry reports whether the outcome of a request is worth retrying
+func (p *retryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if attempt >= p.maxRetries || req.Context().Err() != nil {
+		return false
+	}
+
+	switch req.Method {
+	case "GET", "HEAD", "PUT", "DELETE", "OPTIONS":
+	default:
+		return false
+	}
+
+	// The body can only be sent again if it can be recreated
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter returns the delay requested by the Retry-After header
+// of a 429 or 503 response, in either delay-seconds or HTTP-date form
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sendWithRetry sends the request, retrying it as per the retry policy
+func (t *TCClient) sendWithRetry(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if t.retry == nil || !t.retry.shouldRetry(req, resp, err, attempt) {
+			return resp, err
+		}
+
+		wait := t.retry.backoff(attempt)
+		if resp != nil {
+			// The server knows better when it can take requests again
+			if retryAfter, ok := parseRetryAfter(resp); ok {
+				wait = retryAfter
+			}
+			// Drain the body so that the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
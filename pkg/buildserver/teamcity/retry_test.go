@@ -0,0 +1,50 @@
+package teamcity
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		baseDelay time.Duration
+		maxDelay  time.Duration
+		attempt   int
+		want      time.Duration // Upper bound of the delays
+	}{
+		{"first attempt", 10 * time.Millisecond, time.Second, 0, 10 * time.Millisecond},
+		{"doubled", 10 * time.Millisecond, time.Second, 3, 80 * time.Millisecond},
+		{"capped", 10 * time.Millisecond, 50 * time.Millisecond, 5, 50 * time.Millisecond},
+		{"uncapped", 10 * time.Millisecond, 0, 5, 320 * time.Millisecond},
+		{"uncapped overflow", 10 * time.Millisecond, 0, 100, time.Duration(1<<63 - 1)},
+		{"no base delay", 0, 50 * time.Millisecond, 2, 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &retryPolicy{baseDelay: tt.baseDelay, maxDelay: tt.maxDelay, rnd: rand.New(rand.NewSource(1))}
+
+			var longest time.Duration
+			for i := 0; i < 200; i++ {
+				d := p.backoff(tt.attempt)
+				if d < 0 || d > tt.want {
+					t.Fatalf("backoff(%d) = %s, want between 0 and %s", tt.attempt, d, tt.want)
+				}
+				if d > longest {
+					longest = d
+				}
+			}
+			if longest < tt.want/2 {
+				t.Errorf("backoff(%d) longest delay = %s, want jitter up to %s", tt.attempt, longest, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffNoDelay(t *testing.T) {
+	p := &retryPolicy{rnd: rand.New(rand.NewSource(1))}
+	if d := p.backoff(3); d != 0 {
+		t.Errorf("backoff(3) = %s, want 0 without base and max delays", d)
+	}
+}
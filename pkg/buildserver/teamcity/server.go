@@ -0,0 +1,56 @@
+package teamcity
+
+// TCMetric is a single value of a server metric,
+// metrics with several values have an entry per value
+type TCMetric struct {
+	Name        string
+	Description string
+	Value       float64
+	Tags        map[string]string
+}
+
+// metricsResponse is the representation of /app/rest/server/metrics
+type metricsResponse struct {
+	Metric []struct {
+		Name         string `json:"name"`
+		Description  string `json:"description"`
+		MetricValues struct {
+			MetricValue []struct {
+				Name       string  `json:"name"`
+				Value      float64 `json:"value"`
+				MetricTags struct {
+					MetricTag []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"metricTag"`
+				} `json:"metricTags"`
+			} `json:"metricValue"`
+		} `json:"metricValues"`
+	} `json:"metric"`
+}
+
+// GetServerMetrics returns the internal metrics of the teamcity server
+// such as the build queue size and JVM memory usage
+func (t *TCClient) GetServerMetrics() ([]TCMetric, error) {
+	var resp metricsResponse
+	if err := t.getJSON(t.restURL("/server/metrics"), &resp); err != nil {
+		return nil, err
+	}
+
+	metrics := []TCMetric{}
+	for _, metric := range resp.Metric {
+		for _, value := range metric.MetricValues.MetricValue {
+			tags := map[string]string{}
+			for _, tag := range value.MetricTags.MetricTag {
+				tags[tag.Name] = tag.Value
+			}
+			metrics = append(metrics, TCMetric{
+				Name:        metric.Name,
+				Description: metric.Description,
+				Value:       value.Value,
+				Tags:        tags,
+			})
+		}
+	}
+	return metrics, nil
+}
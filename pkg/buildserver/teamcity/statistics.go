@@ -0,0 +1,111 @@
+package teamcity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// GetBuildStatistics returns the statistic values reported by a build,
+// such as BuildDuration and the code coverage, keyed by statistic name
+func (t *TCClient) GetBuildStatistics(id int) (map[string]string, error) {
+	var statistics TCBuildProperties
+	if err := buildNotFound(t.getJSON(t.restURL("/builds/id:%d/statistics", id), &statistics)); err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for _, statistic := range statistics.Property {
+		result[statistic.Name] = statistic.Value
+	}
+	return result, nil
+}
+
+// GetCoverage returns the code coverage percentages reported by a build
+func (t *TCClient) GetCoverage(id int) (TCCoverage, error) {
+	var coverage TCCoverage
+	statistics, err := t.GetBuildStatistics(id)
+	if err != nil {
+		return coverage, err
+	}
+
+	for key, value := range map[string]*float64{
+		"CodeCoverageS": &coverage.Statement,
+		"CodeCoverageL": &coverage.Line,
+		"CodeCoverageR": &coverage.Branch,
+		"CodeCoverageM": &coverage.Method,
+		"CodeCoverageC": &coverage.Class,
+		"CodeCoverageB": &coverage.Block,
+	} {
+		if raw, ok := statistics[key]; ok {
+			if *value, err = strconv.ParseFloat(raw, 64); err != nil {
+				return coverage, err
+			}
+		}
+	}
+	return coverage, nil
+}
+
+/*
+GetBuildDurationStats returns the minimum, maximum, mean, median and
+95th percentile of the duration of the last count finished builds of
+the build pipeline, as reported by their BuildDuration statistic
+
+Builds that did not report their duration are left out.
+*/
+func (t *TCClient) GetBuildDurationStats(buildTypeID string, count int) (TCDurationStats, error) {
+	var stats TCDurationStats
+	builds, err := t.GetFinishedBuilds(buildTypeID, count)
+	if err != nil {
+		return stats, err
+	}
+
+	durations := []time.Duration{}
+	for _, build := range builds.Builds {
+		statistics, err := t.GetBuildStatistics(build.ID)
+		if err != nil {
+			return stats, err
+		}
+		raw, ok := statistics["BuildDuration"]
+		if !ok {
+			continue
+		}
+		// BuildDuration is reported in milliseconds
+		millis, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return stats, err
+		}
+		durations = append(durations, time.Duration(millis)*time.Millisecond)
+	}
+
+	if len(durations) == 0 {
+		return stats, fmt.Errorf("No finished builds of build pipeline %s reported their duration", buildTypeID)
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	stats.Count = len(durations)
+	stats.Min = durations[0]
+	stats.Max = durations[len(durations)-1]
+	stats.Mean = total / time.Duration(len(durations))
+	stats.P50 = percentile(durations, 50)
+	stats.P95 = percentile(durations, 95)
+	return stats, nil
+}
+
+// percentile returns the p-th percentile of the sorted
+// durations using the nearest rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,111 @@
+package teamcity_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity/teamcitytest"
+)
+
+// buildsResponse is a builds list served by teamcity with the default fields
+const buildsResponse = `{
+  "count": 4,
+  "href": "/app/rest/builds/?locator=buildType:(id:Proj_Build),branch:(default:any),state:any,count:4",
+  "nextHref": "/app/rest/builds/?locator=buildType:(id:Proj_Build),branch:(default:any),state:any,count:4,start:4",
+  "build": [
+    {
+      "id": 3402,
+      "buildTypeId": "Proj_Build",
+      "number": "129",
+      "status": "SUCCESS",
+      "state": "running",
+      "branchName": "main",
+      "defaultBranch": true,
+      "percentageComplete": 42,
+      "href": "/app/rest/builds/id:3402",
+      "webUrl": "https://ci.example.com/viewLog.html?buildId=3402&buildTypeId=Proj_Build"
+    },
+    {
+      "id": 3401,
+      "buildTypeId": "Proj_Build",
+      "number": "128",
+      "status": "SUCCESS",
+      "state": "finished",
+      "branchName": "main",
+      "defaultBranch": true,
+      "composite": true,
+      "href": "/app/rest/builds/id:3401",
+      "webUrl": "https://ci.example.com/viewLog.html?buildId=3401&buildTypeId=Proj_Build",
+      "finishOnAgentDate": "20240105T101500+0000"
+    },
+    {
+      "id": 3400,
+      "buildTypeId": "Proj_Build",
+      "number": "127",
+      "status": "FAILURE",
+      "state": "finished",
+      "branchName": "feature/login",
+      "personal": true,
+      "customized": true,
+      "href": "/app/rest/builds/id:3400",
+      "webUrl": "https://ci.example.com/viewLog.html?buildId=3400&buildTypeId=Proj_Build",
+      "finishOnAgentDate": "20240105T093000+0000"
+    },
+    {
+      "id": 3399,
+      "buildTypeId": "Proj_Build",
+      "number": "126",
+      "status": "UNKNOWN",
+      "state": "finished",
+      "branchName": "feature/login",
+      "failedToStart": true,
+      "href": "/app/rest/builds/id:3399",
+      "webUrl": "https://ci.example.com/viewLog.html?buildId=3399&buildTypeId=Proj_Build",
+      "finishOnAgentDate": "20240105T090000+0000"
+    }
+  ]
+}`
+
+func TestStrictDecodingBuilds(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	server.Handle(http.MethodGet, "/builds/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(buildsResponse))
+	})
+	client := server.Client(teamcity.WithStrictDecoding(true))
+
+	builds, err := client.GetAllBuilds(teamcity.TCQueryParams{BuildTypeID: "Proj_Build", AllBranches: true, Count: 4})
+	if err != nil {
+		t.Fatalf("GetAllBuilds() error = %v", err)
+	}
+	if builds.Count != 4 || len(builds.Builds) != 4 {
+		t.Fatalf("GetAllBuilds() = %d builds, count %d, want 4", len(builds.Builds), builds.Count)
+	}
+	if builds.NextHref == "" {
+		t.Errorf("GetAllBuilds() nextHref is empty")
+	}
+	if first := builds.Builds[0]; !first.DefaultBranch || first.PercentageComplete != 42 {
+		t.Errorf("GetAllBuilds() first build = %+v, want the running build of the default branch", first)
+	}
+	if !builds.Builds[2].Personal || !builds.Builds[3].FailedToStart {
+		t.Errorf("GetAllBuilds() personal = %t, failedToStart = %t, want true", builds.Builds[2].Personal, builds.Builds[3].FailedToStart)
+	}
+}
+
+func TestStrictDecodingUnknownField(t *testing.T) {
+	server := teamcitytest.NewServer()
+	defer server.Close()
+	server.Handle(http.MethodGet, "/builds/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"count":1,"build":[{"id":1,"newField":true}]}`))
+	})
+
+	if _, err := server.Client(teamcity.WithStrictDecoding(true)).GetAllBuilds(teamcity.TCQueryParams{}); err == nil {
+		t.Errorf("GetAllBuilds() in strict mode error = nil, want an unknown field error")
+	}
+	if _, err := server.Client().GetAllBuilds(teamcity.TCQueryParams{}); err != nil {
+		t.Errorf("GetAllBuilds() error = %v, want unknown fields ignored", err)
+	}
+}
@@ -2,29 +2,73 @@ package teamcity
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"log"
+	"mime"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
+	pathpkg "path"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// Environment variables read by the constructors
+// when the token or serverURL is not provided
+const (
+	TokenEnvVar     = "TEAMCITY_TOKEN"
+	ServerURLEnvVar = "TEAMCITY_URL"
+)
+
 // TCClient is client object to talk to teamcity
+//
+// A TCClient is safe for concurrent use by multiple goroutines,
+// its settings are only written by the constructor and its options.
+//
+// Methods returning a build id return -1 along with an error.
 type TCClient struct {
 	client    *http.Client
+	transport *http.Transport
 	token     string
 	serverURL string
+	guest     bool
+	retry     *retryPolicy
+	breaker   *circuitBreaker
+	calls     *callRecorder
+	logBodies bool
+	logger    *log.Logger
+
+	baseCtx         context.Context
+	tokenProvider   func() (string, error)
+	requestIDHeader string
+	pollInterval    time.Duration
+	apiVersion      string
+	strictDecoding  bool
+
+	// optionErr is the error of an option that could not be applied,
+	// returned by every call so that it does not go unnoticed
+	optionErr error
 }
 
-// NewTeamcityClient ...
+// NewTeamcityClient returns a client to talk to the teamcity server
+// at serverURL. The serverURL may contain a context path when teamcity
+// is served from a subdirectory, e.g. https://ci.example.com/teamcity,
+// the REST API paths are appended to it.
+// An empty serverURL or token is read from the TEAMCITY_URL and
+// TEAMCITY_TOKEN environment variables respectively.
 func NewTeamcityClient(
 	requestTimeout, dialTimeout, tlsHandshakeTimeout time.Duration,
 	serverURL, token string,
 	insecure bool,
+	opts ...TCClientOption,
 ) *TCClient {
 	tr := &http.Transport{
 		Dial: (&net.Dialer{
@@ -36,52 +80,149 @@ func NewTeamcityClient(
 	}
 
 	client := &http.Client{
-		Timeout:   requestTimeout,
-		Transport: tr,
+		Timeout:       requestTimeout,
+		Transport:     tr,
+		CheckRedirect: checkRedirect,
+	}
+
+	if token == "" {
+		token = os.Getenv(TokenEnvVar)
 	}
 
-	return &TCClient{
+	t := &TCClient{
 		client:    client,
-		serverURL: serverURL,
+		transport: tr,
+		// Trailing slashes would end up as double slashes in request URLs
+		serverURL: strings.TrimRight(serverURLOrEnv(serverURL), "/"),
 		// Trim the bearer from the token, to keep the API backward compatible
 		// with previous versions were the client had to add the Bearer to the
 		// token beforehand.
-		token: strings.TrimPrefix(token, "Bearer "),
+		token:  strings.TrimPrefix(token, "Bearer "),
+		logger: log.Default(),
+
+		pollInterval: DefaultPollInterval,
 	}
-}
 
-// GetBuild returns build details
-// for the provided id
-func (t *TCClient) GetBuild(id int, buildDetails interface{}) (err error) {
+	for _, opt := range opts {
+		opt(t)
+	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/app/rest/builds/id:%d", t.serverURL, id), nil)
-	if err != nil {
-		return err
+	return t
+}
+
+// NewTeamcityClientE is same as NewTeamcityClient but validates
+// the settings first, it returns an error instead of a broken client
+// if the serverURL cannot be parsed or has no scheme or host, or if
+// certificate validation is skipped while a CA pool or a client
+// certificate is provided, which is most likely a misconfiguration,
+// or if an option could not be applied.
+func NewTeamcityClientE(
+	requestTimeout, dialTimeout, tlsHandshakeTimeout time.Duration,
+	serverURL, token string,
+	insecure bool,
+	opts ...TCClientOption,
+) (*TCClient, error) {
+	if err := validateServerURL(serverURLOrEnv(serverURL)); err != nil {
+		return nil, err
 	}
-	t.setAuthorizationHeader(req.Header)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	t := NewTeamcityClient(requestTimeout, dialTimeout, tlsHandshakeTimeout, serverURL, token, insecure, opts...)
+	if t.optionErr != nil {
+		return nil, t.optionErr
+	}
+	if err := validateTLSConfig(t.transport); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
 
-	resp, err := t.client.Do(req)
-	if err != nil {
-		log.Println(err.Error())
-		return
+// serverURLOrEnv returns the serverURL, falling back
+// to the environment variable when it is empty
+func serverURLOrEnv(serverURL string) string {
+	if serverURL == "" {
+		return os.Getenv(ServerURLEnvVar)
 	}
+	return serverURL
+}
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+// validateServerURL checks that the serverURL is an absolute URL
+func validateServerURL(serverURL string) error {
+	u, err := url.Parse(serverURL)
 	if err != nil {
-		log.Println(err.Error())
-		return
+		return fmt.Errorf("Invalid teamcity server URL %q: %s", serverURL, err.Error())
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("Invalid teamcity server URL %q, expected scheme and host e.g. https://teamcity.example.com", serverURL)
 	}
+	return nil
+}
 
-	err = json.Unmarshal(body, &buildDetails)
-	if err != nil {
-		log.Println(err.Error())
-		return
+// validateTLSConfig checks that certificate validation is not
+// skipped while trusted CAs or client certificates are provided
+func validateTLSConfig(tr *http.Transport) error {
+	if tr == nil || tr.TLSClientConfig == nil || !tr.TLSClientConfig.InsecureSkipVerify {
+		return nil
+	}
+	if tr.TLSClientConfig.RootCAs != nil || len(tr.TLSClientConfig.Certificates) > 0 {
+		return errors.New("Insecure client cannot be combined with custom root CAs or client certificates")
 	}
+	return nil
+}
 
-	return
+// NewTeamcityGuestClient returns a client that talks to teamcity
+// using guest authentication. No token is sent with the requests
+// and all the calls are made against the /guestAuth REST root,
+// so only the resources visible to the guest user can be read.
+func NewTeamcityGuestClient(
+	requestTimeout, dialTimeout, tlsHandshakeTimeout time.Duration,
+	serverURL string,
+	insecure bool,
+	opts ...TCClientOption,
+) *TCClient {
+	t := NewTeamcityClient(requestTimeout, dialTimeout, tlsHandshakeTimeout, serverURL, "", insecure, opts...)
+	t.guest = true
+	return t
+}
+
+// HTTPClient returns the underlying http.Client, it can be used
+// to configure things like a CookieJar that the client doesn't expose.
+// It is a copy of the client passed to WithHTTPClient if any.
+func (t *TCClient) HTTPClient() *http.Client {
+	return t.client
+}
+
+// GetBuild returns build details
+// for the provided id
+func (t *TCClient) GetBuild(id int, buildDetails interface{}) (err error) {
+	return t.GetBuildContext(context.Background(), id, buildDetails)
+}
+
+// GetBuildContext is same as GetBuild but the request is bound
+// to ctx, a deadline set on ctx overrides the client request timeout
+func (t *TCClient) GetBuildContext(ctx context.Context, id int, buildDetails interface{}) error {
+	return buildNotFound(t.doJSON(ctx, "GET", t.restURL("/builds/id:%d", id), nil, buildDetails))
+}
+
+// GetBuildFields returns build details for the provided id
+// limited to the provided fields, e.g. "id,state,status,webUrl".
+// It is useful to cut down the response size when polling a build.
+func (t *TCClient) GetBuildFields(id int, fields string, out interface{}) error {
+	return t.GetBuildFieldsContext(context.Background(), id, fields, out)
+}
+
+// GetBuildFieldsContext is same as GetBuildFields but the request is bound to ctx
+func (t *TCClient) GetBuildFieldsContext(ctx context.Context, id int, fields string, out interface{}) error {
+	return buildNotFound(t.doJSON(ctx, "GET", t.restURL("/builds/id:%d?fields=%s", id, url.QueryEscape(fields)), nil, out))
+}
+
+// GetBuildRevisions returns the exact revisions, one per VCS root,
+// that were checked out by the build
+func (t *TCClient) GetBuildRevisions(id int) ([]TCRevision, error) {
+	var buildDetails TCBuildDetails
+	err := t.GetBuildFields(id, "revisions(revision(version,vcsBranchName,vcs-root-instance(id,vcs-root-id,name)))", &buildDetails)
+	if err != nil || buildDetails.Revisions == nil {
+		return nil, err
+	}
+	return buildDetails.Revisions.Revision, nil
 }
 
 /*
@@ -93,31 +234,118 @@ branch is the branch name on which build will be triggered
 
 params is a map containing env variables and other overrides that
 user wants to provide
+
+comment is sent as a JSON string, so quotes, newlines and URLs
+reach teamcity intact without any escaping by the caller
 */
 func (t *TCClient) StartBuild(
 	buildTypeID, branch, comment string,
 	params map[string]string,
 	snapshotDependencies map[string]int,
 	artifactDependencies map[string]int) (int, error) {
+	buildDetails, err := t.StartBuildDetailed(buildTypeID, branch, comment, params, snapshotDependencies, artifactDependencies)
+	if err != nil {
+		return -1, err
+	}
+	return buildDetails.ID, nil
+}
+
+// StartBuildDetailed adds a build to the build queue same as StartBuild,
+// but returns the queued build as sent back by teamcity, including
+// its state, href and webUrl.
+func (t *TCClient) StartBuildDetailed(
+	buildTypeID, branch, comment string,
+	params map[string]string,
+	snapshotDependencies map[string]int,
+	artifactDependencies map[string]int) (TCBuildDetails, error) {
+	return t.StartBuildWithParams(TCStartBuildParams{
+		BuildTypeID:          buildTypeID,
+		Branch:               branch,
+		Comment:              comment,
+		Params:               params,
+		SnapshotDependencies: snapshotDependencies,
+		ArtifactDependencies: artifactDependencies,
+	})
+}
+
+// StartBuildWithParams adds a build to the build queue as described
+// by params and returns the queued build as sent back by teamcity.
+// It returns an *APIError if teamcity rejects the build.
+func (t *TCClient) StartBuildWithParams(params TCStartBuildParams) (TCBuildDetails, error) {
+	return t.StartBuildWithParamsContext(context.Background(), params)
+}
+
+// StartBuildWithParamsContext is same as StartBuildWithParams
+// but the request is bound to ctx
+func (t *TCClient) StartBuildWithParamsContext(ctx context.Context, params TCStartBuildParams) (TCBuildDetails, error) {
 	var buildDetails TCBuildDetails
 
+	payload := params.payload()
+
+	requestPayload, err := json.Marshal(payload)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return buildDetails, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		t.restURL("/buildQueue"),
+		bytes.NewBuffer(requestPayload))
+	if err != nil {
+		return buildDetails, err
+	}
+	t.logRequest(req, string(requestPayload))
+	t.setAuthorizationHeader(req.Header)
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return buildDetails, err
+	}
+
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return buildDetails, err
+	}
+
+	err = t.newDecoder(resp.Body).Decode(&buildDetails)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return buildDetails, err
+	}
+
+	t.logResponse(resp, fmt.Sprint(buildDetails))
+	return buildDetails, nil
+}
+
+// payload builds the request payload to add a build to the queue
+func (p TCStartBuildParams) payload() TCBuildPayload {
 	payload := TCBuildPayload{
 		BuildType: TCBuildType{
-			ID: buildTypeID,
+			ID: p.BuildTypeID,
 		},
 		Comment: TCBuildComment{
-			Text: comment,
+			Text: p.Comment,
 		},
 		Properties: TCBuildProperties{
 			Property: []TCBuildProperty{},
 		},
-		Personal:   "False",
-		BranchName: branch,
+		Personal:   strconv.FormatBool(p.Personal),
+		BranchName: p.Branch,
 	}
 
 	// Add params to properties
-	for k, v := range params {
-		payload.Properties.Property = append(payload.Properties.Property, TCBuildProperty{k, v})
+	for k, v := range p.Params {
+		payload.Properties.Property = append(payload.Properties.Property, TCBuildProperty{Name: k, Value: v})
+	}
+
+	// Add typed parameters to properties
+	for _, param := range p.Parameters {
+		payload.Properties.Property = append(payload.Properties.Property, param.property())
 	}
 
 	snapDeps := TCBuildSnapshotDependencies{
@@ -128,12 +356,12 @@ func (t *TCClient) StartBuild(
 	}
 
 	// Add snapshot dependencies to request
-	for k, v := range snapshotDependencies {
+	for k, v := range p.SnapshotDependencies {
 		snapDeps.Builds = append(snapDeps.Builds, TCBuildDetails{ID: v, BuildTypeID: k})
 	}
 
 	// Add artifact dependencies to request
-	for k, v := range artifactDependencies {
+	for k, v := range p.ArtifactDependencies {
 		artfDeps.Builds = append(artfDeps.Builds, TCBuildDetails{ID: v, BuildTypeID: k})
 	}
 
@@ -145,56 +373,62 @@ func (t *TCClient) StartBuild(
 		payload.ArtifactDependencies = &artfDeps
 	}
 
-	requestPayload, err := json.Marshal(payload)
-	if err != nil {
-		log.Println(err.Error())
-		return -1, err
-	}
-
-	log.Println(string(requestPayload))
-
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf("%s/app/rest/buildQueue", t.serverURL),
-		bytes.NewBuffer(requestPayload))
-	if err != nil {
-		return -1, err
+	// Run the build on the provided agent
+	if p.AgentID != 0 {
+		payload.Agent = &TCAgent{ID: p.AgentID}
 	}
-	t.setAuthorizationHeader(req.Header)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
 
-	resp, err := t.client.Do(req)
-	if err != nil {
-		log.Println(err.Error())
-		return -1, err
+	// Pin the build to the provided revisions
+	if len(p.Revisions) > 0 {
+		payload.Revisions = &TCRevisions{Revision: []TCRevision{}}
+		for k, v := range p.Revisions {
+			payload.Revisions.Revision = append(payload.Revisions.Revision, TCRevision{
+				Version:         v,
+				VcsRootInstance: &TCVcsRootInstance{VcsRootID: k},
+			})
+		}
 	}
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Println(err.Error())
-		return -1, err
+	if p.RebuildAllDependencies || len(p.RebuildDependencies) > 0 {
+		payload.TriggeringOptions = &TCTriggeringOptions{RebuildAllDependencies: p.RebuildAllDependencies}
 	}
 
-	log.Printf(string(body))
-	err = json.Unmarshal(body, &buildDetails)
-	if err != nil {
-		log.Println(err.Error())
-		return -1, err
+	// Force a rebuild of specific snapshot dependencies,
+	// the others are reused when teamcity finds a suitable build
+	if len(p.RebuildDependencies) > 0 {
+		payload.TriggeringOptions.RebuildDependencies = &TCBuildTypes{BuildType: []TCBuildType{}}
+		for _, buildTypeID := range p.RebuildDependencies {
+			payload.TriggeringOptions.RebuildDependencies.BuildType = append(
+				payload.TriggeringOptions.RebuildDependencies.BuildType, TCBuildType{ID: buildTypeID})
+		}
 	}
 
-	log.Println(buildDetails)
-	return buildDetails.ID, nil
+	return payload
 }
 
 // CancelQueuedBuild cancels a build that is currently
 // queued in the BuildQueue
 // If the build has already started or finished,
-// this call will fail
+// this call will fail with an *APIError
 func (t *TCClient) CancelQueuedBuild(id int, comment string) error {
-	// var buildDetails TCBuildDetails
+	return t.cancelBuild(context.Background(), t.restURL("/buildQueue/%d", id), comment)
+}
+
+// StopBuild stops a running build
+func (t *TCClient) StopBuild(id int, comment string) error {
+	return t.StopBuildContext(context.Background(), id, comment)
+}
+
+// StopBuildContext is same as StopBuild but the request is bound to ctx.
+// It returns an *APIError if teamcity rejects the stop, wrapping
+// ErrBuildNotFound if the build does not exist.
+func (t *TCClient) StopBuildContext(ctx context.Context, id int, comment string) error {
+	return t.cancelBuild(ctx, t.restURL("/builds/%d", id), comment)
+}
 
+// cancelBuild sends the request cancelling the queued or running build
+// at requestURL, without adding it back into the queue
+func (t *TCClient) cancelBuild(ctx context.Context, requestURL, comment string) error {
 	payload := TCBuildStopPayload{
 		Comment:        comment,
 		ReaddIntoQueue: "false",
@@ -202,89 +436,83 @@ func (t *TCClient) CancelQueuedBuild(id int, comment string) error {
 
 	requestPayload, err := json.Marshal(payload)
 	if err != nil {
-		log.Println(err.Error())
+		t.logger.Println(err.Error())
 		return err
 	}
 
-	log.Println(string(requestPayload))
-
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf("%s/app/rest/buildQueue/%d", t.serverURL, id),
-		bytes.NewBuffer(requestPayload))
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(requestPayload))
 	if err != nil {
 		return err
 	}
+	t.logRequest(req, string(requestPayload))
 	t.setAuthorizationHeader(req.Header)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Content-Type", "application/json")
-
-	resp, err := t.client.Do(req)
+	resp, err := t.do(req)
 	if err != nil {
-		log.Println(err.Error())
+		t.logger.Println(err.Error())
 		return err
 	}
 
 	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Println(err.Error())
-		return err
+	if err = checkResponse(resp); err != nil {
+		return buildNotFound(err)
 	}
 
-	/* err = json.Unmarshal(body, &buildDetails)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Println(err.Error())
+		t.logger.Println(err.Error())
 		return err
 	}
 
-	log.Println(buildDetails) */
-	log.Println(string(body))
+	t.logResponse(resp, string(body))
 	return nil
 }
 
-// StopBuild stops a running build
-func (t *TCClient) StopBuild(id int, comment string) error {
-	// var buildDetails TCBuildDetails
-
+// StopAndRequeueBuild stops a running build and adds it back into
+// the build queue, e.g. to run it on another agent. It returns the
+// id of the queued build as sent back by teamcity, -1 on error.
+func (t *TCClient) StopAndRequeueBuild(id int, comment string) (int, error) {
 	payload := TCBuildStopPayload{
 		Comment:        comment,
-		ReaddIntoQueue: "false",
+		ReaddIntoQueue: "true",
 	}
 
-	requestPayload, err := json.Marshal(payload)
+	var queued TCBuildDetails
+	err := t.doJSON(context.Background(), "POST", t.restURL("/builds/id:%d", id), payload, &queued)
 	if err != nil {
-		log.Println(err.Error())
-		return err
+		return -1, buildNotFound(err)
 	}
+	return queued.ID, nil
+}
 
-	log.Println(string(requestPayload))
+// DeleteBuild deletes a finished build along with its artifacts and logs
+func (t *TCClient) DeleteBuild(ctx context.Context, id int) error {
+	return buildNotFound(t.doJSON(ctx, "DELETE", t.restURL("/builds/id:%d", id), nil, nil))
+}
 
-	req, err := http.NewRequest(
-		"POST",
-		fmt.Sprintf("%s/app/rest/builds/%d", t.serverURL, id),
-		bytes.NewBuffer(requestPayload))
-	if err != nil {
+// StopBuildWithParams stops a running build as described by params.
+// When the build is to be marked as failed, it waits for the build to
+// be finished, until ctx is done, to change its status.
+func (t *TCClient) StopBuildWithParams(ctx context.Context, params TCStopBuildParams) error {
+	// Only wait for the build and change its status once
+	// teamcity has accepted to stop it
+	if err := t.StopBuildContext(ctx, params.ID, params.Comment); err != nil {
 		return err
 	}
-	t.setAuthorizationHeader(req.Header)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
-	resp, err := t.client.Do(req)
-	if err != nil {
-		log.Println(err.Error())
-		return err
+	if !params.MarkAsFailed {
+		return nil
 	}
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Println(err.Error())
+	if _, err := t.WaitForBuild(ctx, params.ID, TCWaitHooks{}); err != nil {
 		return err
 	}
 
-	log.Println(string(body))
-	return nil
+	payload := TCBuildStatusUpdate{
+		Status:  StatusFailure,
+		Comment: params.Comment,
+	}
+	return buildNotFound(t.doJSON(ctx, "PUT", t.restURL("/builds/id:%d/status", params.ID), payload, nil))
 }
 
 /*
@@ -297,94 +525,588 @@ id is the build id from which the artifact will be fetched
 It returns content of the file as array of bytes, content type of that file and error object if any
 */
 func (t *TCClient) GetArtifactTextFile(path string, id int) ([]byte, string, error) {
+	return t.GetArtifactTextFileContext(context.Background(), path, id)
+}
+
+// GetArtifactTextFileContext is same as GetArtifactTextFile but the request
+// is bound to ctx, a deadline set on ctx overrides the client request timeout
+func (t *TCClient) GetArtifactTextFileContext(ctx context.Context, path string, id int) ([]byte, string, error) {
+	fileContent, contentType, _, err := t.getArtifactTextFile(ctx, path, id)
+	return fileContent, contentType, err
+}
+
+/*
+GetArtifactTextFileWithName fetches the content of an artifact file
+along with the file name suggested by teamcity
+
+path is the relative path of the file in teamcity artifacts
+
+id is the build id from which the artifact will be fetched
+
+It returns content of the file as array of bytes, content type of that file,
+file name parsed from the Content-Disposition header and error object if any.
+If the header is absent, the last segment of path is returned as the file name.
+*/
+func (t *TCClient) GetArtifactTextFileWithName(path string, id int) ([]byte, string, string, error) {
+	return t.getArtifactTextFile(context.Background(), path, id)
+}
+
+func (t *TCClient) getArtifactTextFile(ctx context.Context, path string, id int) ([]byte, string, string, error) {
 	var fileContent []byte
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/app/rest/builds/id:%d/artifacts/content/%s", t.serverURL, id, path), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", t.restURL("/builds/id:%d/artifacts/content/%s", id, path), nil)
 	if err != nil {
-		return nil, "", err
+		return nil, "", "", err
 	}
 	t.setAuthorizationHeader(req.Header)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	// Artifacts can be of any type, a JSON Accept header
+	// gets some proxies to reject the download
+	req.Header.Add("Accept", "*/*")
 
-	resp, err := t.client.Do(req)
+	resp, err := t.do(req)
 	if err != nil {
-		log.Println(err.Error())
-		return fileContent, "", err
+		t.logger.Println(err.Error())
+		return fileContent, "", "", err
 	}
 
 	defer resp.Body.Close()
-	fileContent, err = ioutil.ReadAll(resp.Body)
+	if err = checkResponse(resp); err != nil {
+		return fileContent, "", "", buildNotFound(err)
+	}
+
+	fileContent, err = io.ReadAll(resp.Body)
 	if err != nil {
-		log.Println(err.Error())
-		return fileContent, "", err
+		t.logger.Println(err.Error())
+		return fileContent, "", "", err
+	}
+	return fileContent, resp.Header.Get("Content-Type"), artifactFileName(resp.Header, path), nil
+}
+
+// artifactFileName returns the file name from the Content-Disposition
+// header, falling back to the last segment of the artifact path
+func artifactFileName(headers http.Header, artifactPath string) string {
+	if _, params, err := mime.ParseMediaType(headers.Get("Content-Disposition")); err == nil {
+		if name := params["filename"]; name != "" {
+			return name
+		}
 	}
-	return fileContent, resp.Header.Get("Content-Type"), nil
+	return pathpkg.Base(artifactPath)
 }
 
 func (t *TCClient) setAuthorizationHeader(headers http.Header) {
+	if t.guest || t.tokenProvider != nil {
+		return
+	}
 	headers.Add("Authorization", fmt.Sprintf("Bearer %s", t.token))
 }
 
+// setProvidedToken sets the Authorization header with a token
+// fetched from the token provider, if the client has one
+func (t *TCClient) setProvidedToken(headers http.Header) error {
+	if t.guest || t.tokenProvider == nil {
+		return nil
+	}
+	token, err := t.tokenProvider()
+	if err != nil {
+		return fmt.Errorf("Failed to get teamcity token: %w", err)
+	}
+	headers.Set("Authorization", fmt.Sprintf("Bearer %s", strings.TrimPrefix(token, "Bearer ")))
+	return nil
+}
+
+// do sends the request using the http client, the request is
+// cancelled along with the base context of the client if any
+func (t *TCClient) do(req *http.Request) (*http.Response, error) {
+	if t.optionErr != nil {
+		return nil, t.optionErr
+	}
+	if t.baseCtx == nil {
+		return t.send(req)
+	}
+	if err := t.baseCtx.Err(); err != nil {
+		return nil, err
+	}
+
+	req, cancel := t.withBaseContext(req)
+	resp, err := t.send(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The context must outlive do until the body is read
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// send sends the request using the http client. If the request context
+// has a deadline, it takes precedence over the client request timeout
+// so that a single call can run longer than the other calls.
+func (t *TCClient) send(req *http.Request) (*http.Response, error) {
+	client := t.client
+	if _, ok := req.Context().Deadline(); ok && client.Timeout > 0 {
+		withoutTimeout := *client
+		withoutTimeout.Timeout = 0
+		client = &withoutTimeout
+	}
+
+	// Ask for gzip explicitly, the transport then leaves the body
+	// as is and it is decompressed below. This also covers proxies
+	// that strip the transparent decompression. Callers may opt out
+	// by setting the header, e.g. for range requests.
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	requestID := t.setRequestID(req)
+
+	if err := t.setProvidedToken(req.Header); err != nil {
+		return nil, err
+	}
+
+	if t.breaker != nil {
+		if err := t.breaker.allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.sendWithRetry(client, req)
+	if t.calls != nil {
+		t.calls.record(req, resp, time.Since(start))
+	}
+	if t.breaker != nil {
+		t.breaker.record(resp, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if requestID != "" {
+		t.logger.Printf("%s %s %s request-id=%s", req.Method, req.URL.Redacted(), resp.Status, requestID)
+	}
+
+	if err = decompressBody(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// logRequest logs the method and URL of a request, along with
+// its body unless bodies are redacted
+func (t *TCClient) logRequest(req *http.Request, body string) {
+	if t.logBodies {
+		t.logger.Printf("%s %s %s", req.Method, req.URL.Redacted(), body)
+		return
+	}
+	t.logger.Printf("%s %s", req.Method, req.URL.Redacted())
+}
+
+// logResponse logs the status of a response, along with
+// its body unless bodies are redacted
+func (t *TCClient) logResponse(resp *http.Response, body string) {
+	if t.logBodies {
+		t.logger.Printf("%s %s", resp.Status, body)
+		return
+	}
+	t.logger.Println(resp.Status)
+}
+
+// gzipBody reads the decompressed content of a response body
+// and closes the underlying body along with the gzip reader
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipBody) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
+
+// decompressBody replaces the body of a gzip encoded response
+// with a reader of the decompressed content
+func decompressBody(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// Empty body, nothing to decompress
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	resp.Body = &gzipBody{Reader: gz, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	return nil
+}
+
+// getJSON makes a GET request to the provided url
+// and decodes the JSON response into out
+func (t *TCClient) getJSON(requestURL string, out interface{}) error {
+	return t.doJSON(context.Background(), "GET", requestURL, nil, out)
+}
+
+// doJSON makes a request to the provided url, payload if not nil
+// is sent as the JSON body of the request. The JSON response
+// is decoded into out if it is not nil. A response with a non 2xx
+// status code is returned as an error.
+func (t *TCClient) doJSON(ctx context.Context, method, requestURL string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		requestPayload, err := json.Marshal(payload)
+		if err != nil {
+			t.logger.Println(err.Error())
+			return err
+		}
+		body = bytes.NewReader(requestPayload)
+	}
+	return t.doRequest(ctx, method, requestURL, body, out)
+}
+
+/*
+Do sends a request to any endpoint of the REST API with the
+authentication and error handling of the client
+
+path is relative to the REST API root, e.g. "/vcs-roots?locator=count:5".
+body, if not nil, is sent as JSON and the JSON response is decoded
+into out, if not nil. Non 2xx responses are returned as *APIError.
+*/
+func (t *TCClient) Do(ctx context.Context, method, path string, body io.Reader, out interface{}) error {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return t.doRequest(ctx, method, t.restURL("%s", path), body, out)
+}
+
+// doRequest sends a JSON request and decodes the JSON response into out
+func (t *TCClient) doRequest(ctx context.Context, method, requestURL string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return err
+	}
+	t.setAuthorizationHeader(req.Header)
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return err
+	}
+
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	// Decode straight from the body instead of buffering it,
+	// an empty body leaves out untouched
+	err = t.newDecoder(resp.Body).Decode(out)
+	if err != nil && err != io.EOF {
+		t.logger.Println(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// newDecoder returns a JSON decoder reading from r
+// that rejects unknown fields in strict mode
+func (t *TCClient) newDecoder(r io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(r)
+	if t.strictDecoding {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder
+}
+
+// doText sends a plain text request, used by the few resources that
+// take and return a bare value, and returns the plain text response
+func (t *TCClient) doText(ctx context.Context, method, requestURL, body string) (string, error) {
+	var reqBody io.Reader
+	if body != "" {
+		reqBody = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return "", err
+	}
+	t.setAuthorizationHeader(req.Header)
+	req.Header.Add("Accept", "text/plain")
+	req.Header.Add("Content-Type", "text/plain")
+
+	resp, err := t.do(req)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err = checkResponse(resp); err != nil {
+		return "", err
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.logger.Println(err.Error())
+		return "", err
+	}
+	return string(content), nil
+}
+
+// restURL returns the absolute URL of a REST API resource,
+// path is formatted with the provided args. The REST root is
+// appended to the serverURL so that any context path is kept.
+func (t *TCClient) restURL(path string, args ...interface{}) string {
+	root := "/app/rest"
+	if t.guest {
+		root = "/guestAuth/app/rest"
+	}
+	if t.apiVersion != "" {
+		root += "/" + t.apiVersion
+	}
+	return t.serverURL + root + fmt.Sprintf(path, args...)
+}
+
 // GetAllBuilds returns the list of builds as per the query params
 // provided by user
 func (t *TCClient) GetAllBuilds(params TCQueryParams) (builds TCBuildSnapshotDependencies, err error) {
-	requestURL := fmt.Sprintf("%s/app/rest/builds/?locator=", t.serverURL)
+	params.Fields = ""
+	err = t.GetBuilds(params, &builds)
+	return
+}
+
+// GetBuilds is same as GetAllBuilds but decodes the builds into out,
+// which can be any type matching the JSON response. The response is
+// limited to params.Fields if set.
+func (t *TCClient) GetBuilds(params TCQueryParams, out interface{}) error {
+	requestURL := t.restURL("/builds/?locator=%s", url.QueryEscape(buildsLocator(params).String()))
+	if params.Fields != "" {
+		requestURL += "&fields=" + url.QueryEscape(params.Fields)
+	}
+	return t.getJSON(requestURL, out)
+}
+
+// buildsLocator returns the builds locator for the query params
+func buildsLocator(params TCQueryParams) *Locator {
+	locator := NewLocator()
 
 	if params.BuildTypeID != "" {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("buildType:(id:%s),", params.BuildTypeID))
+		locator.BuildType(params.BuildTypeID)
 	}
 
 	if params.Branch != "" {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("branch:(name:%s),", params.Branch))
+		locator.Branch(params.Branch)
+	} else if params.AllBranches {
+		locator.AnyBranch()
+	}
+
+	if params.Status != "" {
+		locator.Status(BuildStatus(params.Status))
 	}
 
 	if params.User != "" {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("user:%s,", params.User))
+		locator.User(params.User)
 	}
 
 	if params.Count > 0 {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("count:%d,", params.Count))
+		locator.Count(int(params.Count))
 	}
 
 	if params.Start > 0 {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("start:%d,", params.Start))
+		locator.Start(int(params.Start))
 	}
 
 	if params.LookupLimit > 0 {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("lookupLimit:%d,", params.LookupLimit))
+		locator.LookupLimit(int(params.LookupLimit))
 	}
 
 	if params.Running {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("running:%t,", params.Running))
+		locator.Running(params.Running)
 	}
 
 	if params.Cancelled {
-		requestURL = fmt.Sprintf("%s%s", requestURL, fmt.Sprintf("cancelled:%t,", params.Cancelled))
+		locator.Cancelled(params.Cancelled)
 	}
 
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return
+	if params.Pinned != nil {
+		locator.Pinned(*params.Pinned)
 	}
 
-	t.setAuthorizationHeader(req.Header)
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "application/json")
+	for _, tag := range params.Tags {
+		locator.Tag(tag)
+	}
+
+	if params.Personal != nil {
+		locator.Personal(*params.Personal)
+	}
+
+	if params.Agent != "" {
+		locator.Agent(params.Agent)
+	}
+
+	return locator
+}
+
+// CountBuilds returns the number of builds matching the query params
+// without fetching the builds themselves. The builds are counted page
+// by page unless Count or Start of the query params are set, in which
+// case they apply to the result. Note that teamcity filters the builds
+// by default: only the finished builds of the default branch that are
+// neither personal nor cancelled are counted unless the query params
+// say otherwise, e.g. with AllBranches.
+func (t *TCClient) CountBuilds(params TCQueryParams) (int, error) {
+	if params.Count > 0 || params.Start > 0 {
+		var builds TCBuildSnapshotDependencies
+		err := t.getJSON(t.restURL("/builds/?locator=%s&fields=count", url.QueryEscape(buildsLocator(params).String())), &builds)
+		if err != nil {
+			return 0, err
+		}
+		return builds.Count, nil
+	}
+	return t.countAllBuilds(buildsLocator(params))
+}
+
+// buildsPageSize is the number of builds fetched or counted at once
+// when all the builds matching a locator are needed, teamcity returns
+// at most 100 builds unless the locator has a count dimension
+const buildsPageSize = 1000
+
+// countAllBuilds counts the builds matching the locator page by page
+func (t *TCClient) countAllBuilds(locator *Locator) (int, error) {
+	total := 0
+	for start := 0; ; start += buildsPageSize {
+		page := locator.clone().Count(buildsPageSize).Start(start)
+
+		var builds TCBuildSnapshotDependencies
+		err := t.getJSON(t.restURL("/builds/?locator=%s&fields=count", url.QueryEscape(page.String())), &builds)
+		if err != nil {
+			return 0, err
+		}
+
+		total += builds.Count
+		if builds.Count < buildsPageSize {
+			return total, nil
+		}
+	}
+}
 
-	resp, err := t.client.Do(req)
+// listAllBuilds fetches the builds matching the locator page by page
+func (t *TCClient) listAllBuilds(locator *Locator) ([]TCBuildDetails, error) {
+	all := []TCBuildDetails{}
+	for start := 0; ; start += buildsPageSize {
+		page := locator.clone().Count(buildsPageSize).Start(start)
+
+		var builds TCBuildSnapshotDependencies
+		if err := t.getJSON(t.restURL("/builds/?locator=%s", url.QueryEscape(page.String())), &builds); err != nil {
+			return nil, err
+		}
+
+		all = append(all, builds.Builds...)
+		if len(builds.Builds) < buildsPageSize {
+			return all, nil
+		}
+	}
+}
+
+// GetPinnedBuilds returns the builds of a build pipeline that are
+// pinned and so protected from clean-up, on any branch, personal and
+// cancelled builds included
+func (t *TCClient) GetPinnedBuilds(buildTypeID string) (TCBuildSnapshotDependencies, error) {
+	builds, err := t.listAllBuilds(NewLocator().
+		BuildType(buildTypeID).
+		Pinned(true).
+		AnyBranch().
+		Dimension("personal", "any").
+		Dimension("canceled", "any"))
 	if err != nil {
-		return
+		return TCBuildSnapshotDependencies{}, err
 	}
+	return TCBuildSnapshotDependencies{Count: len(builds), Builds: builds}, nil
+}
 
-	defer resp.Body.Close()
-	respBody, err := ioutil.ReadAll(resp.Body)
+// GetFinishedBuilds returns the last count builds of a build pipeline
+// that are finished, leaving out the personal and cancelled builds
+func (t *TCClient) GetFinishedBuilds(buildTypeID string, count int) (builds TCBuildSnapshotDependencies, err error) {
+	locator := NewLocator().
+		BuildType(buildTypeID).
+		State(StateFinished).
+		Personal(false).
+		Cancelled(false).
+		Count(count)
+	err = t.getJSON(t.restURL("/builds/?locator=%s", url.QueryEscape(locator.String())), &builds)
+	return
+}
+
+// GetDependentBuilds returns the builds that depend on the build,
+// directly or transitively, through snapshot dependencies. Builds of
+// any branch and state are returned, personal and cancelled builds
+// included.
+func (t *TCClient) GetDependentBuilds(id int) ([]TCBuildDetails, error) {
+	// snapshotDependency:(from:...) finds the builds depending on the build,
+	// snapshotDependency:(to:...) would find the builds it depends on
+	return t.listAllBuilds(NewLocator().
+		Nested("snapshotDependency", NewLocator().
+			Nested("from", NewLocator().Dimension("id", strconv.Itoa(id)))).
+		AnyBranch().
+		Dimension("state", "any").
+		Dimension("personal", "any").
+		Dimension("canceled", "any"))
+}
+
+// GetBuildsByRevision returns the builds that include the revision,
+// e.g. a commit sha, of the VCS root. Builds of any branch and state
+// are returned, personal and cancelled builds included.
+func (t *TCClient) GetBuildsByRevision(vcsRootID, revision string) (TCBuildSnapshotDependencies, error) {
+	builds, err := t.listAllBuilds(NewLocator().
+		Revision(vcsRootID, revision).
+		AnyBranch().
+		Dimension("state", "any").
+		Dimension("personal", "any").
+		Dimension("canceled", "any"))
 	if err != nil {
-		return
+		return TCBuildSnapshotDependencies{}, err
 	}
+	return TCBuildSnapshotDependencies{Count: len(builds), Builds: builds}, nil
+}
 
-	if err = json.Unmarshal(respBody, &builds); err != nil {
-		return
+// dependencyBuildFields are the fields requested
+// for the builds a build depends on
+const dependencyBuildFields = "build(id,buildTypeId,number,status,state,branchName,href,webUrl)"
+
+// GetSnapshotDependencies returns the builds the build
+// used as snapshot dependencies, as resolved by teamcity
+func (t *TCClient) GetSnapshotDependencies(id int) ([]TCBuildDetails, error) {
+	var build TCBuildDetails
+	if err := t.GetBuildFields(id, "snapshot-dependencies("+dependencyBuildFields+")", &build); err != nil {
+		return nil, err
 	}
+	if build.SnapshotDependencies == nil {
+		return []TCBuildDetails{}, nil
+	}
+	return build.SnapshotDependencies.Builds, nil
+}
 
-	return
+// GetArtifactDependencies returns the builds the build
+// pulled artifacts from, as resolved by teamcity
+func (t *TCClient) GetArtifactDependencies(id int) ([]TCBuildDetails, error) {
+	var build TCBuildDetails
+	if err := t.GetBuildFields(id, "artifact-dependencies("+dependencyBuildFields+")", &build); err != nil {
+		return nil, err
+	}
+	if build.ArtifactDependencies == nil {
+		return []TCBuildDetails{}, nil
+	}
+	return build.ArtifactDependencies.Builds, nil
 }
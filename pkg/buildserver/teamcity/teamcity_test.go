@@ -0,0 +1,66 @@
+package teamcity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverURL string
+		guest     bool
+		opts      []TCClientOption
+		want      string
+	}{
+		{
+			name:      "server at the root",
+			serverURL: "https://ci.example.com",
+			want:      "https://ci.example.com/app/rest/builds/id:42",
+		},
+		{
+			name:      "context path",
+			serverURL: "https://ci.example.com/teamcity",
+			want:      "https://ci.example.com/teamcity/app/rest/builds/id:42",
+		},
+		{
+			name:      "context path with trailing slash",
+			serverURL: "https://ci.example.com/teamcity/",
+			want:      "https://ci.example.com/teamcity/app/rest/builds/id:42",
+		},
+		{
+			name:      "guest root",
+			serverURL: "https://ci.example.com/teamcity",
+			guest:     true,
+			want:      "https://ci.example.com/teamcity/guestAuth/app/rest/builds/id:42",
+		},
+		{
+			name:      "pinned API version",
+			serverURL: "https://ci.example.com/teamcity",
+			opts:      []TCClientOption{WithAPIVersion("/2018.1/")},
+			want:      "https://ci.example.com/teamcity/app/rest/2018.1/builds/id:42",
+		},
+		{
+			name:      "guest root with pinned API version",
+			serverURL: "https://ci.example.com",
+			guest:     true,
+			opts:      []TCClientOption{WithAPIVersion("2018.1")},
+			want:      "https://ci.example.com/guestAuth/app/rest/2018.1/builds/id:42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var client *TCClient
+			if tt.guest {
+				client = NewTeamcityGuestClient(time.Second, time.Second, time.Second, tt.serverURL, false, tt.opts...)
+			} else {
+				client = NewTeamcityClient(time.Second, time.Second, time.Second, tt.serverURL, "token", false, tt.opts...)
+			}
+
+			if got := client.restURL("/builds/id:%d", 42); got != tt.want {
+				t.Errorf("restURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Package teamcitytest provides a fake teamcity server to test the code
+using the teamcity client without a live server
+
+The fake keeps builds in memory and serves the endpoints used by
+GetBuild, StartBuild, GetAllBuilds and DeleteBuild. Any other endpoint, or any
+of these ones, can be programmed with Handle.
+
+	server := teamcitytest.NewServer()
+	defer server.Close()
+
+	server.AddBuild(teamcity.TCBuildDetails{ID: 1, BuildTypeID: "Pipeline", State: teamcity.StateFinished})
+	client := server.Client()
+*/
+package teamcitytest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+)
+
+// Token is the token the client returned by Server.Client sends
+const Token = "teamcitytest-token"
+
+// Server is a fake teamcity server backed by an httptest.Server
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	builds   map[int]teamcity.TCBuildDetails
+	started  []teamcity.TCBuildPayload
+	nextID   int
+	handlers map[string]http.HandlerFunc
+}
+
+// NewServer starts a fake teamcity server, it must be closed with Close
+func NewServer() *Server {
+	s := &Server{
+		builds:   map[int]teamcity.TCBuildDetails{},
+		nextID:   1,
+		handlers: map[string]http.HandlerFunc{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client talking to the fake server
+func (s *Server) Client(opts ...teamcity.TCClientOption) *teamcity.TCClient {
+	return teamcity.NewTeamcityClient(5*time.Second, 5*time.Second, 5*time.Second, s.URL, Token, false, opts...)
+}
+
+// AddBuild adds a build to the server or replaces the build with the same id
+func (s *Server) AddBuild(build teamcity.TCBuildDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.builds[build.ID] = build
+	if build.ID >= s.nextID {
+		s.nextID = build.ID + 1
+	}
+}
+
+// Build returns the build with the id, including the builds
+// queued through the server
+func (s *Server) Build(id int) (teamcity.TCBuildDetails, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	build, ok := s.builds[id]
+	return build, ok
+}
+
+// StartedBuilds returns the payloads of the builds queued
+// through the server, in the order they were received
+func (s *Server) StartedBuilds() []teamcity.TCBuildPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]teamcity.TCBuildPayload{}, s.started...)
+}
+
+// Handle makes the server answer the requests with the method and the
+// path relative to the REST API root, e.g. "/builds/id:1", with handler.
+// Programmed handlers take precedence over the built-in endpoints.
+func (s *Server) Handle(method, path string, handler http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method+" "+path] = handler
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/guestAuth/app/rest"):
+		path = strings.TrimPrefix(path, "/guestAuth/app/rest")
+	case strings.HasPrefix(path, "/app/rest"):
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		path = strings.TrimPrefix(path, "/app/rest")
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	handler, ok := s.handlers[r.Method+" "+path]
+	s.mu.Unlock()
+	if ok {
+		handler(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && path == "/buildQueue":
+		s.startBuild(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "/builds/id:"):
+		s.getBuild(w, strings.TrimPrefix(path, "/builds/id:"))
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "/builds/id:"):
+		s.deleteBuild(w, strings.TrimPrefix(path, "/builds/id:"))
+	case r.Method == http.MethodGet && (path == "/builds" || path == "/builds/"):
+		s.getBuilds(w, r.URL.Query().Get("locator"))
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported request %s %s", r.Method, path), http.StatusNotFound)
+	}
+}
+
+func (s *Server) startBuild(w http.ResponseWriter, r *http.Request) {
+	var payload teamcity.TCBuildPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	build := teamcity.TCBuildDetails{
+		ID:          s.nextID,
+		BuildTypeID: payload.BuildType.ID,
+		BranchName:  payload.BranchName,
+		Comment:     payload.Comment,
+		State:       teamcity.StateQueued,
+		Href:        fmt.Sprintf("/app/rest/buildQueue/id:%d", s.nextID),
+		WebURL:      fmt.Sprintf("%s/viewQueued.html?itemId=%d", s.URL, s.nextID),
+	}
+	s.builds[build.ID] = build
+	s.started = append(s.started, payload)
+	s.nextID++
+	s.mu.Unlock()
+
+	writeJSON(w, build)
+}
+
+func (s *Server) getBuild(w http.ResponseWriter, rawID string) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		http.Error(w, "Error: Invalid build id "+rawID, http.StatusBadRequest)
+		return
+	}
+
+	build, ok := s.Build(id)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Error: No build found by id '%d'.", id), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, build)
+}
+
+func (s *Server) deleteBuild(w http.ResponseWriter, rawID string) {
+	id, err := strconv.Atoi(rawID)
+	if err != nil {
+		http.Error(w, "Error: Invalid build id "+rawID, http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	_, ok := s.builds[id]
+	delete(s.builds, id)
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("Error: No build found by id '%d'.", id), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getBuilds serves the builds matching the buildType, branch, status,
+// state, running, personal, canceled, pinned, count and start dimensions
+// of the locator, newest first. Like teamcity, at most defaultCount builds
+// are served when the locator has no count dimension. The other
+// dimensions are ignored.
+// defaultCount is the number of builds teamcity serves
+// when the locator has no count dimension
+const defaultCount = 100
+
+func (s *Server) getBuilds(w http.ResponseWriter, locator string) {
+	dimensions := parseLocator(locator)
+
+	s.mu.Lock()
+	matching := []teamcity.TCBuildDetails{}
+	for _, build := range s.builds {
+		if matches(build, dimensions) {
+			matching = append(matching, build)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(matching, func(i, j int) bool { return matching[i].ID > matching[j].ID })
+
+	if start, err := strconv.Atoi(dimensions["start"]); err == nil {
+		if start > len(matching) {
+			start = len(matching)
+		}
+		matching = matching[start:]
+	}
+	count, err := strconv.Atoi(dimensions["count"])
+	if err != nil {
+		count = defaultCount
+	}
+	if count < len(matching) {
+		matching = matching[:count]
+	}
+
+	writeJSON(w, teamcity.TCBuildSnapshotDependencies{
+		Count:  len(matching),
+		Builds: matching,
+	})
+}
+
+// matches reports whether the build matches the locator dimensions.
+// Like teamcity, only the finished builds of the default branch that are
+// neither personal nor cancelled match unless the locator says otherwise.
+func matches(build teamcity.TCBuildDetails, dimensions map[string]string) bool {
+	if buildType, ok := dimensions["buildType"]; ok && parseLocator(buildType)["id"] != build.BuildTypeID {
+		return false
+	}
+	if !branchMatches(build, dimensions) {
+		return false
+	}
+	if status, ok := dimensions["status"]; ok && !strings.EqualFold(status, string(build.Status)) {
+		return false
+	}
+	if state, ok := dimensions["state"]; ok {
+		if state != "any" && state != string(build.State) {
+			return false
+		}
+	} else if running, ok := dimensions["running"]; ok {
+		if running != "any" && (running == "true") != (build.State == teamcity.StateRunning) {
+			return false
+		}
+	} else if build.State != teamcity.StateFinished {
+		return false
+	}
+	if !flagMatches(dimensions, "personal", "false", build.Personal) ||
+		!flagMatches(dimensions, "canceled", "false", build.CanceledInfo != nil) ||
+		!flagMatches(dimensions, "pinned", "any", build.PinInfo != nil && build.PinInfo.Status) {
+		return false
+	}
+	return true
+}
+
+// branchMatches reports whether the build matches the branch dimension,
+// the builds without a branch are on the default branch
+func branchMatches(build teamcity.TCBuildDetails, dimensions map[string]string) bool {
+	branch := parseLocator(dimensions["branch"])
+	if name, ok := branch["name"]; ok {
+		return name == build.BranchName
+	}
+	if branch["default"] == "any" {
+		return true
+	}
+	return build.BranchName == "" || build.DefaultBranch
+}
+
+// flagMatches reports whether the flag of the build matches the true,
+// false or any value of the dimension, or def when it is missing
+func flagMatches(dimensions map[string]string, name, def string, flag bool) bool {
+	value, ok := dimensions[name]
+	if !ok {
+		value = def
+	}
+	return value == "any" || (value == "true") == flag
+}
+
+// parseLocator returns the top level dimensions of a locator with
+// their value unescaped, nested locators are left as is for parsing
+func parseLocator(locator string) map[string]string {
+	dimensions := map[string]string{}
+	for _, dimension := range splitLocator(locator) {
+		name, value := dimension, ""
+		if i := strings.Index(dimension, ":"); i >= 0 {
+			name, value = dimension[:i], dimension[i+1:]
+		}
+		dimensions[name] = unescapeValue(value)
+	}
+	return dimensions
+}
+
+// splitLocator splits a locator on the commas outside of parentheses
+func splitLocator(locator string) []string {
+	parts := []string{}
+	depth, start := 0, 0
+	for i, r := range locator {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, locator[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(locator) {
+		parts = append(parts, locator[start:])
+	}
+	return parts
+}
+
+// unescapeValue strips the parentheses around a value
+// and decodes the base64 encoded values
+func unescapeValue(value string) string {
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	if encoded := strings.TrimPrefix(value, "$base64:"); encoded != value {
+		if decoded, err := base64.URLEncoding.DecodeString(encoded); err == nil {
+			return string(decoded)
+		}
+	}
+	return value
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,142 @@
+package teamcitytest
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/raghuP9/buildserver-client/pkg/buildserver/teamcity"
+)
+
+func TestSplitLocator(t *testing.T) {
+	tests := []struct {
+		locator string
+		want    []string
+	}{
+		{"", []string{}},
+		{"count:10", []string{"count:10"}},
+		{"buildType:(id:A),count:10", []string{"buildType:(id:A)", "count:10"}},
+		{"snapshotDependency:(from:(id:1),recursive:true),state:any", []string{"snapshotDependency:(from:(id:1),recursive:true)", "state:any"}},
+		{"branch:(name:(a,b)),tag:x", []string{"branch:(name:(a,b))", "tag:x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locator, func(t *testing.T) {
+			if got := splitLocator(tt.locator); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitLocator(%q) = %q, want %q", tt.locator, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeValue(t *testing.T) {
+	encoded := base64.URLEncoding.EncodeToString([]byte("fix (part"))
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"main", "main"},
+		{"(feature/x, y)", "feature/x, y"},
+		{"((nested))", "(nested)"},
+		{"($base64:" + encoded + ")", "fix (part"},
+		{"($base64:not base64)", "$base64:not base64"},
+		{"(unclosed", "(unclosed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := unescapeValue(tt.value); got != tt.want {
+				t.Errorf("unescapeValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLocator(t *testing.T) {
+	locator := teamcity.NewLocator().
+		BuildType("Pipeline").
+		Branch("fix (part").
+		Tag("a:b").
+		Dimension("running", "any").
+		Count(5).
+		String()
+
+	dimensions := parseLocator(locator)
+	want := map[string]string{
+		"buildType": "id:Pipeline",
+		"branch":    "name:" + base64Value("fix (part"),
+		"tag":       "a:b",
+		"running":   "any",
+		"count":     "5",
+	}
+	if !reflect.DeepEqual(dimensions, want) {
+		t.Fatalf("parseLocator(%q) = %q, want %q", locator, dimensions, want)
+	}
+	if name := parseLocator(dimensions["branch"])["name"]; name != "fix (part" {
+		t.Errorf("nested branch name = %q, want %q", name, "fix (part")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	finished := teamcity.TCBuildDetails{ID: 1, BuildTypeID: "Pipeline", Status: teamcity.StatusSuccess, State: teamcity.StateFinished}
+	feature := finished
+	feature.BranchName = "feature"
+	onDefault := finished
+	onDefault.BranchName, onDefault.DefaultBranch = "main", true
+	running := finished
+	running.State = teamcity.StateRunning
+	queued := finished
+	queued.State = teamcity.StateQueued
+	personal := finished
+	personal.Personal = true
+	canceled := finished
+	canceled.CanceledInfo = &teamcity.TCCanceledInfo{}
+	pinned := finished
+	pinned.PinInfo = &teamcity.TCPinInfo{Status: true}
+
+	tests := []struct {
+		name    string
+		build   teamcity.TCBuildDetails
+		locator string
+		want    bool
+	}{
+		{"no dimensions", finished, "", true},
+		{"build type", finished, "buildType:(id:Pipeline)", true},
+		{"other build type", finished, "buildType:(id:Other)", false},
+		{"default branch", onDefault, "", true},
+		{"other branch by default", feature, "", false},
+		{"branch name", feature, "branch:(name:feature)", true},
+		{"other branch name", onDefault, "branch:(name:feature)", false},
+		{"any branch", feature, "branch:(default:any)", true},
+		{"status", finished, "status:SUCCESS", true},
+		{"status case", finished, "status:success", true},
+		{"other status", finished, "status:FAILURE", false},
+		{"running by default", running, "", false},
+		{"state", running, "state:running", true},
+		{"other state", queued, "state:running", false},
+		{"any state", queued, "state:any", true},
+		{"running", running, "running:true", true},
+		{"not running", running, "running:false", false},
+		{"running any", running, "running:any", true},
+		{"personal by default", personal, "", false},
+		{"personal", personal, "personal:true", true},
+		{"personal any", finished, "personal:any", true},
+		{"not personal", finished, "personal:true", false},
+		{"canceled by default", canceled, "", false},
+		{"canceled", canceled, "canceled:true", true},
+		{"canceled any", canceled, "canceled:any", true},
+		{"pinned by default", pinned, "", true},
+		{"pinned", pinned, "pinned:true", true},
+		{"not pinned", finished, "pinned:true", false},
+		{"unpinned", pinned, "pinned:false", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matches(tt.build, parseLocator(tt.locator)); got != tt.want {
+				t.Errorf("matches(%s) = %t, want %t", tt.locator, got, tt.want)
+			}
+		})
+	}
+}
+
+func base64Value(value string) string {
+	return "($base64:" + base64.URLEncoding.EncodeToString([]byte(value)) + ")"
+}
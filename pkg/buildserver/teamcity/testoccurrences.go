@@ -0,0 +1,80 @@
+package teamcity
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// GetTestOccurrence returns a single test occurrence including its
+// details, the failure message and stack trace, that are left out when
+// listing test occurrences. occurrenceID is the id of the occurrence as
+// returned by teamcity, e.g. "build:(id:123),id:2000000".
+func (t *TCClient) GetTestOccurrence(occurrenceID string) (TCTestOccurrence, error) {
+	var occurrence TCTestOccurrence
+	err := t.getJSON(t.restURL("/testOccurrences/%s", url.PathEscape(occurrenceID)), &occurrence)
+	return occurrence, err
+}
+
+// testOccurrencesPageSize is the number of test occurrences
+// requested at once when all of them are needed
+const testOccurrencesPageSize = 1000
+
+// getFailedTests returns the names of the tests that failed in a build
+func (t *TCClient) getFailedTests(buildID int) (map[string]bool, error) {
+	failed := map[string]bool{}
+	for start := 0; ; start += testOccurrencesPageSize {
+		locator := NewLocator().
+			Nested("build", NewLocator().Dimension("id", strconv.Itoa(buildID))).
+			Dimension("status", "FAILURE").
+			Count(testOccurrencesPageSize).
+			Start(start)
+
+		var occurrences TCTestOccurrences
+		err := t.getJSON(t.restURL("/testOccurrences?locator=%s&fields=testOccurrence(name)",
+			url.QueryEscape(locator.String())), &occurrences)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, occurrence := range occurrences.TestOccurrence {
+			failed[occurrence.Name] = true
+		}
+		if len(occurrences.TestOccurrence) < testOccurrencesPageSize {
+			return failed, nil
+		}
+	}
+}
+
+// DiffTestResults compares the failed tests of buildB with those of buildA.
+// It returns the tests failing in buildB only, the tests failing in buildA
+// only and the tests failing in both, each sorted by name.
+func (t *TCClient) DiffTestResults(buildA, buildB int) (newFailures, fixed, stillFailing []string, err error) {
+	failedA, err := t.getFailedTests(buildA)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	failedB, err := t.getFailedTests(buildB)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	newFailures, fixed, stillFailing = []string{}, []string{}, []string{}
+	for name := range failedB {
+		if failedA[name] {
+			stillFailing = append(stillFailing, name)
+		} else {
+			newFailures = append(newFailures, name)
+		}
+	}
+	for name := range failedA {
+		if !failedB[name] {
+			fixed = append(fixed, name)
+		}
+	}
+
+	sort.Strings(newFailures)
+	sort.Strings(fixed)
+	sort.Strings(stillFailing)
+	return newFailures, fixed, stillFailing, nil
+}
@@ -0,0 +1,90 @@
+package teamcity
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultPollInterval is the interval between two requests
+// when the client polls teamcity for the state of a build
+const DefaultPollInterval = 10 * time.Second
+
+// TCWaitHooks are optional callbacks called by WaitForBuild
+// as the build advances
+type TCWaitHooks struct {
+	// OnProgress is called with the percentage complete of the
+	// running build every time it changes
+	OnProgress func(pct int)
+
+	// OnQueuePosition is called with the position of the build
+	// in the build queue every time it changes while it is queued
+	OnQueuePosition func(position int)
+}
+
+// WaitForBuild polls the build until it is finished and returns it.
+// It stops with the error that failed the polling, including the ctx
+// error when ctx is done.
+func (t *TCClient) WaitForBuild(ctx context.Context, id int, hooks TCWaitHooks) (TCBuildDetails, error) {
+	ticker := time.NewTicker(t.pollInterval)
+	defer ticker.Stop()
+
+	progress, position := -1, -1
+	for {
+		var details TCBuildDetails
+		if err := t.GetBuildContext(ctx, id, &details); err != nil {
+			return details, err
+		}
+		if details.State == StateFinished {
+			return details, nil
+		}
+
+		if hooks.OnQueuePosition != nil && details.State == StateQueued {
+			current, err := t.GetQueuePosition(id)
+			if err != nil && !errors.Is(err, ErrBuildNotFound) {
+				return details, err
+			}
+			// Not found when the build started since it was fetched
+			if err == nil && current != position {
+				position = current
+				hooks.OnQueuePosition(position)
+			}
+		}
+
+		if hooks.OnProgress != nil && details.RunningInfo != nil &&
+			details.RunningInfo.PercentageComplete != progress {
+			progress = details.RunningInfo.PercentageComplete
+			hooks.OnProgress(progress)
+		}
+
+		select {
+		case <-ctx.Done():
+			return details, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SubscribeBuildFinish polls the build in a background goroutine until it
+// is finished. The finished build is sent on the first channel, or the error
+// that stopped the polling on the second one, including the ctx error when
+// ctx is done. Exactly one value is sent and then both channels are closed.
+func (t *TCClient) SubscribeBuildFinish(ctx context.Context, id int) (<-chan TCBuildDetails, <-chan error) {
+	// Buffered so that the goroutine never blocks if the caller stops listening
+	result := make(chan TCBuildDetails, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(result)
+		defer close(errs)
+
+		details, err := t.WaitForBuild(ctx, id, TCWaitHooks{})
+		if err != nil {
+			errs <- err
+			return
+		}
+		result <- details
+	}()
+
+	return result, errs
+}